@@ -0,0 +1,127 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codec
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/elastic/apm-data/model/modelpb"
+)
+
+// DefaultMaxFrameSize is the default maximum size of a single event
+// frame accepted by BatchReader.
+const DefaultMaxFrameSize = 64 << 20 // 64MiB
+
+// MarshalBatch encodes the events in b into a single byte slice, as a
+// stream of length-delimited frames. Each frame consists of the size of
+// the encoded event as an unsigned varint, followed by the event encoded
+// with MarshalVT. Nil events are encoded as empty events.
+//
+// The output is allocated once, sized using SizeVT.
+func MarshalBatch(b modelpb.Batch) ([]byte, error) {
+	size := 0
+	for _, event := range b {
+		n := event.SizeVT()
+		size += uvarintSize(uint64(n)) + n
+	}
+	buf := make([]byte, size)
+	// Events are marshaled back to front, as MarshalToSizedBufferVT
+	// writes to the end of the buffer it is given.
+	i := len(buf)
+	for j := len(b) - 1; j >= 0; j-- {
+		n, err := b[j].MarshalToSizedBufferVT(buf[:i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode event %d: %w", j, err)
+		}
+		i -= n
+		i -= uvarintSize(uint64(n))
+		binary.PutUvarint(buf[i:], uint64(n))
+	}
+	return buf, nil
+}
+
+// EncodeBatch writes the events in b to w, as described in MarshalBatch.
+func EncodeBatch(w io.Writer, b modelpb.Batch) error {
+	buf, err := MarshalBatch(b)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(buf)
+	return err
+}
+
+// BatchReader reads events one at a time from a stream of length-delimited
+// frames, as written by EncodeBatch.
+type BatchReader struct {
+	r   *bufio.Reader
+	buf []byte
+
+	// MaxFrameSize is the maximum size of a single frame. Frames larger
+	// than this are rejected with an error. Defaults to DefaultMaxFrameSize.
+	MaxFrameSize int
+}
+
+// NewBatchReader returns a new BatchReader that reads from r.
+func NewBatchReader(r io.Reader) *BatchReader {
+	return &BatchReader{
+		r:            bufio.NewReader(r),
+		MaxFrameSize: DefaultMaxFrameSize,
+	}
+}
+
+// Next decodes the next event in the stream into out.
+//
+// Next returns io.EOF when the stream ends cleanly on a frame boundary,
+// or an error wrapping io.ErrUnexpectedEOF if the final frame is truncated.
+func (r *BatchReader) Next(out *modelpb.APMEvent) error {
+	size, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		if err == io.EOF {
+			return io.EOF
+		}
+		return fmt.Errorf("failed to read frame size: %w", err)
+	}
+	if size > uint64(r.MaxFrameSize) {
+		return fmt.Errorf("frame size %d exceeds maximum %d", size, r.MaxFrameSize)
+	}
+	if uint64(cap(r.buf)) < size {
+		r.buf = make([]byte, size)
+	}
+	r.buf = r.buf[:size]
+	if _, err := io.ReadFull(r.r, r.buf); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return fmt.Errorf("failed to read frame: %w", err)
+	}
+	out.Reset()
+	return out.UnmarshalVT(r.buf)
+}
+
+func uvarintSize(x uint64) int {
+	n := 1
+	for x >= 0x80 {
+		x >>= 7
+		n++
+	}
+	return n
+}
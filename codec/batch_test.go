@@ -0,0 +1,152 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codec
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/elastic/apm-data/model/modelpb"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func testBatch(t testing.TB) modelpb.Batch {
+	return modelpb.Batch{
+		fullEvent(t),
+		{Message: "small"},
+		{},
+		fullEvent(t),
+	}
+}
+
+func TestBatchRoundTrip(t *testing.T) {
+	batch := testBatch(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, EncodeBatch(&buf, batch))
+
+	var decoded modelpb.Batch
+	r := NewBatchReader(&buf)
+	for {
+		event := &modelpb.APMEvent{}
+		err := r.Next(event)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		decoded = append(decoded, event)
+	}
+	assert.Empty(t, cmp.Diff(batch, decoded, protocmp.Transform()))
+}
+
+func TestBatchEmpty(t *testing.T) {
+	b, err := MarshalBatch(nil)
+	require.NoError(t, err)
+	assert.Empty(t, b)
+
+	err = NewBatchReader(bytes.NewReader(b)).Next(&modelpb.APMEvent{})
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestBatchTruncated(t *testing.T) {
+	b, err := MarshalBatch(testBatch(t))
+	require.NoError(t, err)
+
+	// Determine the frame boundaries, so we can check that truncating the
+	// stream anywhere else is detected.
+	boundaries := map[int]bool{0: true}
+	r := bytes.NewReader(b)
+	br := NewBatchReader(r)
+	for {
+		if err := br.Next(&modelpb.APMEvent{}); err == io.EOF {
+			break
+		}
+		boundaries[len(b)-r.Len()-br.r.Buffered()] = true
+	}
+	require.True(t, boundaries[len(b)])
+
+	for n := 0; n < len(b); n++ {
+		r := NewBatchReader(bytes.NewReader(b[:n]))
+		var err error
+		for err == nil {
+			err = r.Next(&modelpb.APMEvent{})
+		}
+		if boundaries[n] {
+			assert.Equal(t, io.EOF, err, "length %d", n)
+		} else {
+			assert.ErrorIs(t, err, io.ErrUnexpectedEOF, "length %d", n)
+		}
+	}
+}
+
+func TestBatchMaxFrameSize(t *testing.T) {
+	event := &modelpb.APMEvent{Message: "0123456789"}
+	b, err := MarshalBatch(modelpb.Batch{event})
+	require.NoError(t, err)
+
+	r := NewBatchReader(bytes.NewReader(b))
+	r.MaxFrameSize = event.SizeVT() - 1
+	assert.EqualError(t, r.Next(&modelpb.APMEvent{}), fmt.Sprintf(
+		"frame size %d exceeds maximum %d", event.SizeVT(), r.MaxFrameSize,
+	))
+}
+
+func TestMarshalBatchAllocs(t *testing.T) {
+	// fullEvent is not used here, as the embedded structpb values are
+	// marshaled using the proto package, which allocates.
+	batch := modelpb.Batch{
+		{Message: "a", Labels: modelpb.Labels{"k": {Value: "v"}}},
+		nil,
+		{Transaction: &modelpb.Transaction{Id: "abc", Name: "def"}},
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		MarshalBatch(batch)
+	})
+	assert.Equal(t, float64(1), allocs)
+}
+
+func BenchmarkBatch(b *testing.B) {
+	batch := make(modelpb.Batch, 100)
+	for i := range batch {
+		batch[i] = fullEvent(b)
+	}
+	encoded, err := MarshalBatch(batch)
+	require.NoError(b, err)
+
+	b.Run("encode", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			EncodeBatch(io.Discard, batch)
+		}
+		b.ReportMetric(float64(len(encoded)), "bytes/op")
+	})
+	b.Run("decode", func(b *testing.B) {
+		b.ReportAllocs()
+		event := &modelpb.APMEvent{}
+		for i := 0; i < b.N; i++ {
+			r := NewBatchReader(bytes.NewReader(encoded))
+			for r.Next(event) == nil {
+			}
+		}
+	})
+}
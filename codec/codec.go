@@ -21,6 +21,8 @@ package codec
 
 import (
 	"context"
+	"io"
+	"sync"
 
 	"go.opentelemetry.io/otel/metric"
 )
@@ -43,39 +45,123 @@ type Codec interface {
 	Decoder
 }
 
-// RecordEncodedBytes decorates an encoder with a metric that records the bytes
-// that have been encoded.
-func RecordEncodedBytes(e Encoder, m metric.Int64Counter) Encoder {
-	return metricsRecorder{encoder: e, encoded: m}
+// AppendEncoder is an Encoder which can append the encoded representation
+// of a type to an existing byte slice, avoiding an allocation when the
+// byte slice has sufficient capacity.
+type AppendEncoder interface {
+	Encoder
+
+	// AppendEncode appends the byte slice representation of a type to
+	// dst, and returns the extended byte slice.
+	AppendEncode(dst []byte, in any) ([]byte, error)
 }
 
-// RecordDecodedBytes decorates a decoder with a metric that records the bytes
-// that have been decoded.
-func RecordDecodedBytes(d Decoder, m metric.Int64Counter) Decoder {
-	return metricsRecorder{decoder: d, decoded: m}
+// StreamEncoder is an Encoder which can write the encoded representation
+// of a type directly to an io.Writer.
+type StreamEncoder interface {
+	Encoder
+
+	// EncodeTo writes the byte slice representation of a type to w.
+	EncodeTo(w io.Writer, in any) error
 }
 
-type metricsRecorder struct {
-	encoder Encoder
-	decoder Decoder
+// ContextEncoder is an Encoder which accepts a context, which is passed
+// through to any telemetry recorded while encoding.
+type ContextEncoder interface {
+	Encoder
+
+	// EncodeContext encodes a type into its byte slice representation.
+	EncodeContext(ctx context.Context, in any) ([]byte, error)
+}
+
+// ContextDecoder is a Decoder which accepts a context, which is passed
+// through to any telemetry recorded while decoding.
+type ContextDecoder interface {
+	Decoder
 
-	encoded metric.Int64Counter
-	decoded metric.Int64Counter
+	// DecodeContext decodes a byte slice representation into its Go type.
+	DecodeContext(ctx context.Context, in []byte, out any) error
 }
 
-// Encode encodes a type into its byte slice representation.
-func (m metricsRecorder) Encode(in any) ([]byte, error) {
-	b, err := m.encoder.Encode(in)
-	if m.encoded != nil {
-		m.encoded.Add(context.Background(), int64(len(b)))
+// EncodeContext encodes in using e.EncodeContext if e is a ContextEncoder,
+// and e.Encode otherwise.
+func EncodeContext(ctx context.Context, e Encoder, in any) ([]byte, error) {
+	if ce, ok := e.(ContextEncoder); ok {
+		return ce.EncodeContext(ctx, in)
 	}
-	return b, err
+	return e.Encode(in)
 }
 
-// Decode decodes a byte slice representation into its Go type.
-func (m metricsRecorder) Decode(in []byte, out any) error {
-	if m.decoded != nil {
-		m.decoded.Add(context.Background(), int64(len(in)))
+// DecodeContext decodes in using d.DecodeContext if d is a ContextDecoder,
+// and d.Decode otherwise.
+func DecodeContext(ctx context.Context, d Decoder, in []byte, out any) error {
+	if cd, ok := d.(ContextDecoder); ok {
+		return cd.DecodeContext(ctx, in, out)
 	}
-	return m.decoder.Decode(in, out)
+	return d.Decode(in, out)
+}
+
+// AppendEncode appends the encoded representation of in to dst, using
+// e.AppendEncode if e is an AppendEncoder, and e.Encode otherwise.
+func AppendEncode(e Encoder, dst []byte, in any) ([]byte, error) {
+	if ae, ok := e.(AppendEncoder); ok {
+		return ae.AppendEncode(dst, in)
+	}
+	b, err := e.Encode(in)
+	if err != nil {
+		return dst, err
+	}
+	return append(dst, b...), nil
+}
+
+// EncodeTo writes the encoded representation of in to w, using e.EncodeTo
+// if e is a StreamEncoder. Otherwise in is encoded into a pooled buffer
+// with AppendEncode, and then written to w.
+func EncodeTo(e Encoder, w io.Writer, in any) error {
+	if se, ok := e.(StreamEncoder); ok {
+		return se.EncodeTo(w, in)
+	}
+	if _, ok := e.(AppendEncoder); !ok {
+		b, err := e.Encode(in)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	}
+	return encodeToPooled(e, w, in)
+}
+
+var encodeBufferPool = sync.Pool{New: func() any { return new([]byte) }}
+
+// encodeToPooled encodes in into a pooled buffer using AppendEncode, and
+// then writes the buffer to w.
+func encodeToPooled(e Encoder, w io.Writer, in any) error {
+	buf := encodeBufferPool.Get().(*[]byte)
+	defer encodeBufferPool.Put(buf)
+	b, err := AppendEncode(e, (*buf)[:0], in)
+	*buf = b
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// RecordEncodedBytes decorates an encoder with a metric that records the bytes
+// that have been encoded.
+func RecordEncodedBytes(e Encoder, m metric.Int64Counter) Encoder {
+	return InstrumentEncoder(e, TelemetryConfig{EncodedBytes: m})
+}
+
+// RecordDecodedBytes decorates a decoder with a metric that records the bytes
+// that have been decoded.
+func RecordDecodedBytes(d Decoder, m metric.Int64Counter) Decoder {
+	return InstrumentDecoder(d, TelemetryConfig{DecodedBytes: m})
+}
+
+// RecordBytes decorates a codec with metrics that record the bytes that
+// have been encoded and decoded. Either metric may be nil.
+func RecordBytes(c Codec, encoded, decoded metric.Int64Counter) Codec {
+	return Instrument(c, TelemetryConfig{EncodedBytes: encoded, DecodedBytes: decoded})
 }
@@ -18,16 +18,22 @@
 package codec
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/elastic/apm-data/model/modelpb"
+	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestMetrics(t *testing.T) {
@@ -81,14 +87,119 @@ func TestMetrics(t *testing.T) {
 	}, metric, metricdatatest.IgnoreTimestamp())
 }
 
+func TestRoundTrip(t *testing.T) {
+	ev := fullEvent(t)
+	codecs := map[string]Codec{
+		"protojson": ProtoJSON{},
+		"vtproto":   VTProto{},
+	}
+	for name, c := range codecs {
+		t.Run("format="+name, func(t *testing.T) {
+			b, err := c.Encode(ev)
+			require.NoError(t, err)
+
+			out := &modelpb.APMEvent{}
+			require.NoError(t, c.Decode(b, out))
+			assert.Empty(t, cmp.Diff(ev, out, protocmp.Transform()))
+		})
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	// JSON encodes APMEvents as Elasticsearch documents, which omit
+	// some fields of fullEvent, so use an event with serialized fields.
+	ev := &modelpb.APMEvent{
+		Timestamp: timestamppb.New(time.Date(2023, 5, 1, 12, 30, 0, 123000000, time.UTC)),
+		Processor: modelpb.LogProcessor(),
+		DataStream: &modelpb.DataStream{
+			Type:      "logs",
+			Dataset:   "apm.app.service",
+			Namespace: "default",
+		},
+		Service: &modelpb.Service{Name: "service", Version: "1.0"},
+		Message: "hello",
+		Labels:  modelpb.Labels{"k": {Value: "v"}},
+		Log:     &modelpb.Log{Level: "info"},
+	}
+	b, err := JSON{}.Encode(ev)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"data_stream.type":"logs"`)
+
+	out := &modelpb.APMEvent{}
+	require.NoError(t, JSON{}.Decode(b, out))
+	assert.Empty(t, cmp.Diff(ev, out, protocmp.Transform()))
+}
+
+func TestProtoJSONInvalidType(t *testing.T) {
+	_, err := ProtoJSON{}.Encode(map[string]any{"a": "b"})
+	assert.EqualError(t, err, "failed to encode, message is map[string]interface {} (not a proto.Message)")
+
+	out := make(map[string]any)
+	err = ProtoJSON{}.Decode([]byte(`{}`), &out)
+	assert.EqualError(t, err, "failed to decode, message is *map[string]interface {} (not a proto.Message)")
+}
+
+func TestAppendEncode(t *testing.T) {
+	ev := fullEvent(t)
+	codecs := map[string]Encoder{
+		"json":      JSON{},
+		"protojson": ProtoJSON{}, // falls back to Encode
+		"vtproto":   VTProto{},
+		"metrics":   RecordEncodedBytes(VTProto{}, nil),
+	}
+	for name, c := range codecs {
+		t.Run("format="+name, func(t *testing.T) {
+			expected, err := c.Encode(ev)
+			require.NoError(t, err)
+
+			prefix := []byte("prefix")
+			b, err := AppendEncode(c, prefix, ev)
+			require.NoError(t, err)
+			assert.Equal(t, "prefix", string(b[:len(prefix)]))
+			assert.Equal(t, expected, b[len(prefix):])
+
+			var buf bytes.Buffer
+			require.NoError(t, EncodeTo(c, &buf, ev))
+			assert.Equal(t, expected, buf.Bytes())
+		})
+	}
+}
+
+func TestAppendEncodeAllocs(t *testing.T) {
+	// fullEvent is not used here, as the embedded structpb values are
+	// marshaled using the proto package, which allocates.
+	ev := &modelpb.APMEvent{
+		Message:     "message",
+		Transaction: &modelpb.Transaction{Id: "abc", Name: "def"},
+	}
+	dst := make([]byte, 0, 1024)
+	allocs := testing.AllocsPerRun(100, func() {
+		VTProto{}.AppendEncode(dst, ev)
+	})
+	assert.Equal(t, float64(0), allocs)
+	allocs = testing.AllocsPerRun(100, func() {
+		VTProto{}.EncodeTo(io.Discard, ev)
+	})
+	assert.Equal(t, float64(0), allocs)
+}
+
+func TestAppendEncodeInvalidType(t *testing.T) {
+	dst := []byte("prefix")
+	b, err := VTProto{}.AppendEncode(dst, map[string]any{"a": "b"})
+	assert.EqualError(t, err, "failed to encode, message is map[string]interface {} (missing vtprotobuf helpers)")
+	assert.Equal(t, dst, b)
+}
+
 func BenchmarkEncode(b *testing.B) {
 	ev := fullEvent(b)
 	codecs := map[string]Codec{
-		"json":    JSON{},
-		"vtproto": VTProto{},
+		"json":      JSON{},
+		"protojson": ProtoJSON{},
+		"vtproto":   VTProto{},
 	}
 	for name, c := range codecs {
 		b.Run("format="+name, func(b *testing.B) {
+			b.ReportAllocs()
 			var output atomic.Int64
 			b.RunParallel(func(p *testing.PB) {
 				var localOutput int64
@@ -101,14 +212,32 @@ func BenchmarkEncode(b *testing.B) {
 			bytePerOp := float64(output.Load()) / float64(b.N)
 			b.ReportMetric(bytePerOp, "bytes/op")
 		})
+		b.Run("format="+name+"/api=append", func(b *testing.B) {
+			b.ReportAllocs()
+			b.RunParallel(func(p *testing.PB) {
+				var buf []byte
+				for p.Next() {
+					buf, _ = AppendEncode(c, buf[:0], ev)
+				}
+			})
+		})
+		b.Run("format="+name+"/api=encodeto", func(b *testing.B) {
+			b.ReportAllocs()
+			b.RunParallel(func(p *testing.PB) {
+				for p.Next() {
+					EncodeTo(c, io.Discard, ev)
+				}
+			})
+		})
 	}
 }
 
 func BenchmarkDecode(b *testing.B) {
 	ev := fullEvent(b)
 	codecs := map[string]Codec{
-		"json":    JSON{},
-		"vtproto": VTProto{},
+		"json":      JSON{},
+		"protojson": ProtoJSON{},
+		"vtproto":   VTProto{},
 	}
 	for name, c := range codecs {
 		encoded, _ := c.Encode(ev)
@@ -0,0 +1,352 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codec
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"go.opentelemetry.io/otel/metric"
+)
+
+// compressionMagic is the first byte of the header written by compressed
+// encoders. It cannot be the first byte of a protobuf message, as field
+// number zero is invalid, nor of a JSON document.
+const compressionMagic = 0x00
+
+// DefaultMaxDecompressedSize is the maximum size of decompressed data
+// used by DecompressDecoder if CompressionConfig.MaxDecompressedSize is
+// zero.
+const DefaultMaxDecompressedSize = 64 << 20
+
+// ErrDecompressedSizeExceeded is returned by DecompressDecoder when the
+// decompressed data exceeds the configured maximum size.
+var ErrDecompressedSizeExceeded = errors.New("decompressed size exceeds limit")
+
+// compressionHeaderSize is the size of the header written by compressed
+// encoders: the magic byte, followed by the compression algorithm.
+const compressionHeaderSize = 2
+
+// CompressionAlgorithm identifies a compression algorithm.
+type CompressionAlgorithm uint8
+
+const (
+	// Gzip compresses using compress/gzip.
+	Gzip CompressionAlgorithm = iota + 1
+
+	// Zlib compresses using compress/zlib.
+	Zlib
+
+	// Flate compresses using compress/flate.
+	Flate
+)
+
+// String returns the name of the compression algorithm.
+func (a CompressionAlgorithm) String() string {
+	switch a {
+	case Gzip:
+		return "gzip"
+	case Zlib:
+		return "zlib"
+	case Flate:
+		return "flate"
+	}
+	return fmt.Sprintf("CompressionAlgorithm(%d)", uint8(a))
+}
+
+// CompressionConfig holds configuration for CompressEncoder and
+// DecompressDecoder.
+type CompressionConfig struct {
+	// Algorithm is the algorithm used for compressing encoded data.
+	//
+	// Algorithm is ignored by DecompressDecoder, which detects the
+	// algorithm from the encoded data.
+	Algorithm CompressionAlgorithm
+
+	// Level is the compression level, as defined by compress/flate.
+	// If Level is zero, flate.DefaultCompression is used. As
+	// flate.NoCompression is also zero, it cannot be selected: encoders
+	// which should not compress should not be wrapped by CompressEncoder.
+	//
+	// Level is ignored by DecompressDecoder.
+	Level int
+
+	// MaxDecompressedSize is the maximum size of decompressed data, which
+	// guards against small payloads expanding without bound. If it is zero,
+	// DefaultMaxDecompressedSize is used; if it is negative, the size is
+	// not limited.
+	//
+	// MaxDecompressedSize is ignored by CompressEncoder.
+	MaxDecompressedSize int64
+
+	// RawBytes, if non-nil, records the number of bytes before
+	// compression, or after decompression.
+	RawBytes metric.Int64Counter
+
+	// CompressedBytes, if non-nil, records the number of bytes after
+	// compression, or before decompression, including the header.
+	CompressedBytes metric.Int64Counter
+}
+
+// CompressEncoder decorates an encoder, compressing its output.
+//
+// The compressed output is prefixed with a small header identifying the
+// compression algorithm, which is used by DecompressDecoder to detect
+// compressed input.
+func CompressEncoder(e Encoder, cfg CompressionConfig) (Encoder, error) {
+	level := cfg.Level
+	if level == 0 {
+		level = flate.DefaultCompression
+	}
+	if level < flate.HuffmanOnly || level > flate.BestCompression {
+		return nil, fmt.Errorf("invalid compression level %d", level)
+	}
+	newWriter, err := writerFunc(cfg.Algorithm, level)
+	if err != nil {
+		return nil, err
+	}
+	return &compressor{
+		encoder:    e,
+		algorithm:  cfg.Algorithm,
+		raw:        cfg.RawBytes,
+		compressed: cfg.CompressedBytes,
+		writers: sync.Pool{New: func() any {
+			return newWriter()
+		}},
+	}, nil
+}
+
+// DecompressDecoder decorates a decoder, decompressing its input if it
+// was encoded by CompressEncoder. Input without a compression header is
+// passed to the decoder unmodified.
+func DecompressDecoder(d Decoder, cfg CompressionConfig) Decoder {
+	maxSize := cfg.MaxDecompressedSize
+	if maxSize == 0 {
+		maxSize = DefaultMaxDecompressedSize
+	}
+	return decompressor{
+		decoder:    d,
+		maxSize:    maxSize,
+		raw:        cfg.RawBytes,
+		compressed: cfg.CompressedBytes,
+	}
+}
+
+type compressWriter interface {
+	io.WriteCloser
+	Reset(io.Writer)
+}
+
+func writerFunc(algorithm CompressionAlgorithm, level int) (func() compressWriter, error) {
+	switch algorithm {
+	case Gzip:
+		return func() compressWriter {
+			w, _ := gzip.NewWriterLevel(nil, level)
+			return w
+		}, nil
+	case Zlib:
+		return func() compressWriter {
+			w, _ := zlib.NewWriterLevel(nil, level)
+			return w
+		}, nil
+	case Flate:
+		return func() compressWriter {
+			w, _ := flate.NewWriter(nil, level)
+			return w
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported compression algorithm %s", algorithm)
+}
+
+type compressor struct {
+	encoder   Encoder
+	algorithm CompressionAlgorithm
+	writers   sync.Pool
+
+	raw        metric.Int64Counter
+	compressed metric.Int64Counter
+}
+
+// Encode encodes a type into its byte slice representation, and then
+// compresses it.
+func (c *compressor) Encode(in any) ([]byte, error) {
+	return c.appendEncode(context.Background(), nil, in)
+}
+
+// EncodeContext encodes a type into its byte slice representation, and
+// then compresses it, passing ctx to the wrapped encoder and metrics.
+func (c *compressor) EncodeContext(ctx context.Context, in any) ([]byte, error) {
+	return c.appendEncode(ctx, nil, in)
+}
+
+// AppendEncode encodes a type into its byte slice representation, and then
+// appends its compressed form to dst.
+func (c *compressor) AppendEncode(dst []byte, in any) ([]byte, error) {
+	return c.appendEncode(context.Background(), dst, in)
+}
+
+func (c *compressor) appendEncode(ctx context.Context, dst []byte, in any) ([]byte, error) {
+	rawBuf := encodeBufferPool.Get().(*[]byte)
+	defer encodeBufferPool.Put(rawBuf)
+	var raw []byte
+	var err error
+	if ce, ok := c.encoder.(ContextEncoder); ok {
+		raw, err = ce.EncodeContext(ctx, in)
+	} else {
+		raw, err = AppendEncode(c.encoder, (*rawBuf)[:0], in)
+		*rawBuf = raw
+	}
+	if err != nil {
+		return dst, err
+	}
+	if c.raw != nil {
+		c.raw.Add(ctx, int64(len(raw)))
+	}
+
+	n := len(dst)
+	buf := bytes.NewBuffer(dst)
+	buf.Grow(compressionHeaderSize + len(raw)/2)
+	buf.WriteByte(compressionMagic)
+	buf.WriteByte(byte(c.algorithm))
+	w := c.writers.Get().(compressWriter)
+	defer func() {
+		// Release buf, which may wrap dst, before pooling the writer.
+		w.Reset(io.Discard)
+		c.writers.Put(w)
+	}()
+	w.Reset(buf)
+	if _, err := w.Write(raw); err != nil {
+		return dst, fmt.Errorf("failed to compress: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return dst, fmt.Errorf("failed to compress: %w", err)
+	}
+
+	b := buf.Bytes()
+	if c.compressed != nil {
+		c.compressed.Add(ctx, int64(len(b)-n))
+	}
+	return b, nil
+}
+
+var (
+	gzipReaders  sync.Pool
+	zlibReaders  sync.Pool
+	flateReaders sync.Pool
+)
+
+type decompressor struct {
+	decoder Decoder
+	maxSize int64
+
+	raw        metric.Int64Counter
+	compressed metric.Int64Counter
+}
+
+// Decode decompresses a byte slice if it has a compression header, and
+// then decodes it into its Go type.
+func (d decompressor) Decode(in []byte, out any) error {
+	return d.DecodeContext(context.Background(), in, out)
+}
+
+// DecodeContext decompresses a byte slice if it has a compression header,
+// and then decodes it into its Go type, passing ctx to the wrapped decoder
+// and metrics.
+func (d decompressor) DecodeContext(ctx context.Context, in []byte, out any) error {
+	if len(in) >= compressionHeaderSize && in[0] == compressionMagic {
+		if d.compressed != nil {
+			d.compressed.Add(ctx, int64(len(in)))
+		}
+		var err error
+		in, err = decompress(CompressionAlgorithm(in[1]), in[compressionHeaderSize:], d.maxSize)
+		if err != nil {
+			return fmt.Errorf("failed to decompress: %w", err)
+		}
+	}
+	if d.raw != nil {
+		d.raw.Add(ctx, int64(len(in)))
+	}
+	return DecodeContext(ctx, d.decoder, in, out)
+}
+
+// decompress decompresses in, returning ErrDecompressedSizeExceeded if
+// the result is larger than maxSize bytes. A negative maxSize means no
+// limit.
+func decompress(algorithm CompressionAlgorithm, in []byte, maxSize int64) ([]byte, error) {
+	var r io.ReadCloser
+	br := bytes.NewReader(in)
+	switch algorithm {
+	case Gzip:
+		gr, ok := gzipReaders.Get().(*gzip.Reader)
+		if !ok {
+			gr = new(gzip.Reader)
+		}
+		defer gzipReaders.Put(gr)
+		if err := gr.Reset(br); err != nil {
+			return nil, err
+		}
+		r = gr
+	case Zlib:
+		zr, ok := zlibReaders.Get().(io.ReadCloser)
+		if !ok {
+			var err error
+			if zr, err = zlib.NewReader(br); err != nil {
+				return nil, err
+			}
+		} else if err := zr.(zlib.Resetter).Reset(br, nil); err != nil {
+			// The reader is reset before its next use,
+			// so it may still be returned to the pool.
+			zlibReaders.Put(zr)
+			return nil, err
+		}
+		defer zlibReaders.Put(zr)
+		r = zr
+	case Flate:
+		fr, ok := flateReaders.Get().(io.ReadCloser)
+		if !ok {
+			fr = flate.NewReader(br)
+		} else if err := fr.(flate.Resetter).Reset(br, nil); err != nil {
+			flateReaders.Put(fr)
+			return nil, err
+		}
+		defer flateReaders.Put(fr)
+		r = fr
+	default:
+		return nil, fmt.Errorf("unsupported compression algorithm %s", algorithm)
+	}
+	var lr io.Reader = r
+	if maxSize >= 0 {
+		// Read one byte past the limit to detect oversized data.
+		lr = io.LimitReader(r, maxSize+1)
+	}
+	out, err := io.ReadAll(lr)
+	if err != nil {
+		return nil, err
+	}
+	if maxSize >= 0 && int64(len(out)) > maxSize {
+		return nil, fmt.Errorf("%w of %d bytes", ErrDecompressedSizeExceeded, maxSize)
+	}
+	return out, r.Close()
+}
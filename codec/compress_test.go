@@ -0,0 +1,204 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codec
+
+import (
+	"compress/flate"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/elastic/apm-data/model/modelpb"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestCompression(t *testing.T) {
+	ev := fullEvent(t)
+	uncompressed, err := VTProto{}.Encode(ev)
+	require.NoError(t, err)
+
+	for _, algorithm := range []CompressionAlgorithm{Gzip, Zlib, Flate} {
+		for _, level := range []int{0, flate.BestSpeed, flate.BestCompression, flate.HuffmanOnly} {
+			t.Run(fmt.Sprintf("algorithm=%s/level=%d", algorithm, level), func(t *testing.T) {
+				encoder, err := CompressEncoder(VTProto{}, CompressionConfig{Algorithm: algorithm, Level: level})
+				require.NoError(t, err)
+				decoder := DecompressDecoder(VTProto{}, CompressionConfig{})
+
+				// Encode more than once to exercise pooled writers and readers.
+				for i := 0; i < 3; i++ {
+					b, err := encoder.Encode(ev)
+					require.NoError(t, err)
+					assert.Equal(t, []byte{compressionMagic, byte(algorithm)}, b[:compressionHeaderSize])
+					if level != flate.HuffmanOnly {
+						assert.Less(t, len(b), len(uncompressed))
+					}
+
+					out := &modelpb.APMEvent{}
+					require.NoError(t, decoder.Decode(b, out))
+					assert.Empty(t, cmp.Diff(ev, out, protocmp.Transform()))
+				}
+			})
+		}
+	}
+}
+
+func TestDecompressUncompressed(t *testing.T) {
+	ev := fullEvent(t)
+	for name, c := range map[string]Codec{
+		"json":    ProtoJSON{},
+		"vtproto": VTProto{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			b, err := c.Encode(ev)
+			require.NoError(t, err)
+
+			out := &modelpb.APMEvent{}
+			require.NoError(t, DecompressDecoder(c, CompressionConfig{}).Decode(b, out))
+			assert.Empty(t, cmp.Diff(ev, out, protocmp.Transform()))
+		})
+	}
+}
+
+func TestCompressionErrors(t *testing.T) {
+	_, err := CompressEncoder(VTProto{}, CompressionConfig{})
+	assert.EqualError(t, err, "unsupported compression algorithm CompressionAlgorithm(0)")
+
+	_, err = CompressEncoder(VTProto{}, CompressionConfig{Algorithm: Gzip, Level: 10})
+	assert.EqualError(t, err, "invalid compression level 10")
+
+	decoder := DecompressDecoder(VTProto{}, CompressionConfig{})
+	err = decoder.Decode([]byte{compressionMagic, 123, 1, 2, 3}, &modelpb.APMEvent{})
+	assert.EqualError(t, err, "failed to decompress: unsupported compression algorithm CompressionAlgorithm(123)")
+
+	err = decoder.Decode([]byte{compressionMagic, byte(Gzip), 1, 2, 3}, &modelpb.APMEvent{})
+	assert.ErrorContains(t, err, "failed to decompress")
+}
+
+func TestDecompressMaxSize(t *testing.T) {
+	raw := make([]byte, 1024)
+	for _, algorithm := range []CompressionAlgorithm{Gzip, Zlib, Flate} {
+		t.Run(algorithm.String(), func(t *testing.T) {
+			encoder, err := CompressEncoder(rawCodec{}, CompressionConfig{Algorithm: algorithm})
+			require.NoError(t, err)
+			b, err := encoder.Encode(raw)
+			require.NoError(t, err)
+
+			var out []byte
+			decoder := DecompressDecoder(rawCodec{}, CompressionConfig{MaxDecompressedSize: 1024})
+			require.NoError(t, decoder.Decode(b, &out))
+			assert.Equal(t, raw, out)
+
+			decoder = DecompressDecoder(rawCodec{}, CompressionConfig{MaxDecompressedSize: 1023})
+			err = decoder.Decode(b, &out)
+			assert.ErrorIs(t, err, ErrDecompressedSizeExceeded)
+			assert.EqualError(t, err, "failed to decompress: decompressed size exceeds limit of 1023 bytes")
+
+			decoder = DecompressDecoder(rawCodec{}, CompressionConfig{MaxDecompressedSize: -1})
+			require.NoError(t, decoder.Decode(b, &out))
+			assert.Equal(t, raw, out)
+		})
+	}
+}
+
+func TestCompressionContext(t *testing.T) {
+	ctx := context.WithValue(context.Background(), testContextKey{}, "value")
+
+	inner := &contextCodec{}
+	encoder, err := CompressEncoder(inner, CompressionConfig{Algorithm: Gzip})
+	require.NoError(t, err)
+	b, err := EncodeContext(ctx, encoder, nil)
+	require.NoError(t, err)
+	require.NoError(t, DecodeContext(ctx, DecompressDecoder(inner, CompressionConfig{}), b, nil))
+	assert.Equal(t, []any{"value", "value"}, inner.values)
+}
+
+func TestCompressionMetrics(t *testing.T) {
+	rdr := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(rdr))
+	meter := mp.Meter("test")
+
+	raw, err := meter.Int64Counter("raw")
+	require.NoError(t, err)
+	compressed, err := meter.Int64Counter("compressed")
+	require.NoError(t, err)
+	cfg := CompressionConfig{Algorithm: Gzip, RawBytes: raw, CompressedBytes: compressed}
+
+	var codec testCodec
+	encoder, err := CompressEncoder(codec, cfg)
+	require.NoError(t, err)
+	b, err := encoder.Encode(nil)
+	require.NoError(t, err)
+	require.NoError(t, DecompressDecoder(codec, cfg).Decode(b, nil))
+
+	var rm metricdata.ResourceMetrics
+	assert.NoError(t, rdr.Collect(context.Background(), &rm))
+	values := make(map[string]int64)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		values[m.Name] = m.Data.(metricdata.Sum[int64]).DataPoints[0].Value
+	}
+	assert.Equal(t, map[string]int64{
+		"raw":        2 * int64(len("dummy")),
+		"compressed": 2 * int64(len(b)),
+	}, values)
+}
+
+// rawCodec encodes and decodes byte slices unmodified.
+type rawCodec struct{}
+
+func (rawCodec) Encode(in any) ([]byte, error) {
+	return in.([]byte), nil
+}
+
+func (rawCodec) Decode(in []byte, out any) error {
+	*out.(*[]byte) = append([]byte(nil), in...)
+	return nil
+}
+
+func BenchmarkCompress(b *testing.B) {
+	ev := fullEvent(b)
+	for _, algorithm := range []CompressionAlgorithm{Gzip, Zlib, Flate} {
+		encoder, err := CompressEncoder(VTProto{}, CompressionConfig{Algorithm: algorithm})
+		require.NoError(b, err)
+		decoder := DecompressDecoder(VTProto{}, CompressionConfig{})
+		encoded, err := encoder.Encode(ev)
+		require.NoError(b, err)
+
+		b.Run("encode/algorithm="+algorithm.String(), func(b *testing.B) {
+			b.ReportAllocs()
+			b.RunParallel(func(p *testing.PB) {
+				for p.Next() {
+					encoder.Encode(ev)
+				}
+			})
+			b.ReportMetric(float64(len(encoded)), "bytes/op")
+		})
+		b.Run("decode/algorithm="+algorithm.String(), func(b *testing.B) {
+			b.ReportAllocs()
+			b.RunParallel(func(p *testing.PB) {
+				for p.Next() {
+					decoder.Decode(encoded, &modelpb.APMEvent{})
+				}
+			})
+		})
+	}
+}
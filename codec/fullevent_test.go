@@ -26,7 +26,7 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-func fullEvent(t *testing.B) *modelpb.APMEvent {
+func fullEvent(t testing.TB) *modelpb.APMEvent {
 	return &modelpb.APMEvent{
 		Timestamp: timestamppb.New(time.Unix(1, 1)),
 		Span: &modelpb.Span{
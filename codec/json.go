@@ -20,9 +20,21 @@ package codec
 
 import (
 	"encoding/json"
+	"io"
+	"sync"
+
+	"go.elastic.co/fastjson"
 )
 
+var fastjsonWriterPool = sync.Pool{New: func() any { return new(fastjson.Writer) }}
+
 // JSON wraps the standard json library.
+//
+// Types implementing json.Marshaler or json.Unmarshaler control their own
+// encoding. In particular, *modelpb.APMEvent is encoded and decoded as an
+// Elasticsearch document, e.g. {"data_stream.type": "logs"}, and not using
+// its Go field names. Decode accepts documents produced by Encode, as well
+// as documents with nested objects.
 type JSON struct{}
 
 // Encode encodes a type into the JSON byte slice representation.
@@ -32,3 +44,46 @@ func (j JSON) Encode(in any) ([]byte, error) { return json.Marshal(in) }
 func (j JSON) Decode(in []byte, out any) error {
 	return json.Unmarshal(in, out)
 }
+
+// AppendEncode appends the JSON encoding of a type to dst. Types which
+// implement fastjson.Marshaler are encoded using a pooled fastjson.Writer,
+// and other types fall back to the standard json library.
+func (j JSON) AppendEncode(dst []byte, in any) ([]byte, error) {
+	m, ok := in.(fastjson.Marshaler)
+	if !ok {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return dst, err
+		}
+		return append(dst, b...), nil
+	}
+	w := fastjsonWriterPool.Get().(*fastjson.Writer)
+	defer fastjsonWriterPool.Put(w)
+	w.Reset()
+	if err := m.MarshalFastJSON(w); err != nil {
+		return dst, err
+	}
+	return append(dst, w.Bytes()...), nil
+}
+
+// EncodeTo writes the JSON encoding of a type to w. Types which implement
+// fastjson.Marshaler are written directly from a pooled fastjson.Writer.
+func (j JSON) EncodeTo(w io.Writer, in any) error {
+	m, ok := in.(fastjson.Marshaler)
+	if !ok {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	}
+	fw := fastjsonWriterPool.Get().(*fastjson.Writer)
+	defer fastjsonWriterPool.Put(fw)
+	fw.Reset()
+	if err := m.MarshalFastJSON(fw); err != nil {
+		return err
+	}
+	_, err := w.Write(fw.Bytes())
+	return err
+}
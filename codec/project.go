@@ -0,0 +1,54 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codec
+
+import "github.com/elastic/apm-data/model/modelpb"
+
+// Project decorates an encoder, encoding only the fields of each
+// *modelpb.APMEvent selected by mask. The input events are not modified.
+// Other types are passed to the encoder unmodified.
+//
+// Project may be used with VTProto, or with JSON to produce Elasticsearch
+// documents holding a subset of fields.
+func Project(e Encoder, mask *modelpb.FieldMask) Encoder {
+	return projector{encoder: e, mask: mask}
+}
+
+type projector struct {
+	encoder Encoder
+	mask    *modelpb.FieldMask
+}
+
+// Encode encodes the selected fields of a type into its byte slice
+// representation.
+func (p projector) Encode(in any) ([]byte, error) {
+	return p.encoder.Encode(p.project(in))
+}
+
+// AppendEncode appends the byte slice representation of the selected
+// fields of a type to dst.
+func (p projector) AppendEncode(dst []byte, in any) ([]byte, error) {
+	return AppendEncode(p.encoder, dst, p.project(in))
+}
+
+func (p projector) project(in any) any {
+	if event, ok := in.(*modelpb.APMEvent); ok {
+		return p.mask.Project(event)
+	}
+	return in
+}
@@ -0,0 +1,70 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codec
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/elastic/apm-data/model/modelpb"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestProject(t *testing.T) {
+	mask, err := modelpb.NewFieldMask("trace.id", "service.name", "event.duration")
+	require.NoError(t, err)
+	ev := fullEvent(t)
+	orig := proto.Clone(ev)
+
+	encoder := Project(VTProto{}, mask)
+	b, err := encoder.Encode(ev)
+	require.NoError(t, err)
+	out := &modelpb.APMEvent{}
+	require.NoError(t, VTProto{}.Decode(b, out))
+	assert.Empty(t, cmp.Diff(&modelpb.APMEvent{
+		Trace:   &modelpb.Trace{Id: ev.Trace.Id},
+		Service: &modelpb.Service{Name: ev.Service.Name},
+		Event:   &modelpb.Event{Duration: ev.Event.Duration},
+	}, out, protocmp.Transform()))
+	assert.Empty(t, cmp.Diff(orig, ev, protocmp.Transform()))
+
+	appended, err := AppendEncode(encoder, nil, ev)
+	require.NoError(t, err)
+	assert.Equal(t, b, appended)
+
+	b, err = Project(JSON{}, mask).Encode(ev)
+	require.NoError(t, err)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(b, &doc))
+	assert.Equal(t, map[string]any{
+		// @timestamp is always present in Elasticsearch documents.
+		"@timestamp": "1970-01-01T00:00:00.000Z",
+		"trace":      map[string]any{"id": ev.Trace.Id},
+		"service":    map[string]any{"name": ev.Service.Name},
+		"event":      map[string]any{"duration": float64(ev.Event.Duration.AsDuration())},
+	}, doc)
+
+	// Other types are encoded unmodified.
+	b, err = Project(JSON{}, mask).Encode(map[string]string{"a": "b"})
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":"b"}`, string(b))
+}
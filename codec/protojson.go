@@ -0,0 +1,51 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codec
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// ProtoJSON is a composite of Encoder and Decoder which uses the canonical
+// protobuf JSON mapping.
+//
+// Unlike JSON, which encodes *modelpb.APMEvent as an Elasticsearch document,
+// ProtoJSON output can be decoded back into the original message without
+// loss of information.
+type ProtoJSON struct{}
+
+// Encode encodes a proto.Message into its protobuf JSON representation.
+func (p ProtoJSON) Encode(in any) ([]byte, error) {
+	m, ok := in.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("failed to encode, message is %T (not a proto.Message)", in)
+	}
+	return protojson.Marshal(m)
+}
+
+// Decode decodes a protobuf JSON-encoded byte slice into a proto.Message.
+func (p ProtoJSON) Decode(in []byte, out any) error {
+	m, ok := out.(proto.Message)
+	if !ok {
+		return fmt.Errorf("failed to decode, message is %T (not a proto.Message)", out)
+	}
+	return protojson.Unmarshal(in, m)
+}
@@ -0,0 +1,144 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codec
+
+import (
+	"fmt"
+	"mime"
+	"sort"
+	"sync"
+)
+
+var defaultRegistry = newRegistry()
+
+func init() {
+	Register("json", []string{"application/json"}, JSON{})
+	Register("protojson", nil, ProtoJSON{})
+	Register("vtproto", []string{
+		"application/x-protobuf",
+		"application/protobuf",
+		"application/vnd.google.protobuf",
+	}, VTProto{})
+}
+
+// Register makes a codec available by name, and by each of the given
+// content types. The built-in codecs are registered as "json", "protojson"
+// and "vtproto".
+//
+// Register panics if c is nil, or if name or any of the content types
+// have already been registered. Decorated codecs, such as those returned
+// by RecordBytes, may be registered under a new name.
+func Register(name string, contentTypes []string, c Codec) {
+	if err := defaultRegistry.register(name, contentTypes, c); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the codec registered with the given name.
+func Lookup(name string) (Codec, bool) {
+	return defaultRegistry.lookup(name)
+}
+
+// ForContentType returns the codec registered for the given content type.
+// Media type parameters, such as charset, are ignored, and the media type
+// is matched case-insensitively.
+func ForContentType(contentType string) (Codec, bool) {
+	return defaultRegistry.forContentType(contentType)
+}
+
+// Names returns the sorted names of all registered codecs.
+func Names() []string {
+	return defaultRegistry.names()
+}
+
+type registry struct {
+	mu            sync.RWMutex
+	byName        map[string]Codec
+	byContentType map[string]Codec
+}
+
+func newRegistry() *registry {
+	return &registry{
+		byName:        make(map[string]Codec),
+		byContentType: make(map[string]Codec),
+	}
+}
+
+func (r *registry) register(name string, contentTypes []string, c Codec) error {
+	if c == nil {
+		return fmt.Errorf("codec %q is nil", name)
+	}
+	mediaTypes := make([]string, len(contentTypes))
+	for i, ct := range contentTypes {
+		mediaType, err := parseMediaType(ct)
+		if err != nil {
+			return fmt.Errorf("invalid content type %q for codec %q: %w", ct, name, err)
+		}
+		mediaTypes[i] = mediaType
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.byName[name]; ok {
+		return fmt.Errorf("codec %q already registered", name)
+	}
+	for _, mediaType := range mediaTypes {
+		if _, ok := r.byContentType[mediaType]; ok {
+			return fmt.Errorf("codec for content type %q already registered", mediaType)
+		}
+	}
+	r.byName[name] = c
+	for _, mediaType := range mediaTypes {
+		r.byContentType[mediaType] = c
+	}
+	return nil
+}
+
+func (r *registry) lookup(name string) (Codec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.byName[name]
+	return c, ok
+}
+
+func (r *registry) forContentType(contentType string) (Codec, bool) {
+	mediaType, err := parseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.byContentType[mediaType]
+	return c, ok
+}
+
+func (r *registry) names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.byName))
+	for name := range r.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func parseMediaType(contentType string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return mediaType, err
+}
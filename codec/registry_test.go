@@ -0,0 +1,110 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codec
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestRegistryBuiltins(t *testing.T) {
+	assert.Equal(t, []string{"json", "protojson", "vtproto"}, Names())
+
+	for name, expected := range map[string]Codec{
+		"json":      JSON{},
+		"protojson": ProtoJSON{},
+		"vtproto":   VTProto{},
+	} {
+		c, ok := Lookup(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, expected, c, name)
+	}
+	_, ok := Lookup("unknown")
+	assert.False(t, ok)
+
+	for contentType, expected := range map[string]Codec{
+		"application/json":                JSON{},
+		"Application/JSON; charset=utf-8": JSON{},
+		"application/x-protobuf":          VTProto{},
+		"application/protobuf":            VTProto{},
+		"application/vnd.google.protobuf": VTProto{},
+	} {
+		c, ok := ForContentType(contentType)
+		assert.True(t, ok, contentType)
+		assert.Equal(t, expected, c, contentType)
+	}
+	for _, contentType := range []string{"", "text/plain", "invalid;;"} {
+		_, ok := ForContentType(contentType)
+		assert.False(t, ok, contentType)
+	}
+}
+
+func TestRegistryErrors(t *testing.T) {
+	r := newRegistry()
+	require.NoError(t, r.register("a", []string{"application/a"}, testCodec{}))
+
+	assert.EqualError(t, r.register("b", nil, nil), `codec "b" is nil`)
+	assert.EqualError(t,
+		r.register("a", nil, testCodec{}),
+		`codec "a" already registered`,
+	)
+	assert.EqualError(t,
+		r.register("b", []string{"APPLICATION/A"}, testCodec{}),
+		`codec for content type "application/a" already registered`,
+	)
+	assert.EqualError(t,
+		r.register("b", []string{""}, testCodec{}),
+		`invalid content type "" for codec "b": mime: no media type`,
+	)
+	// Failed registrations must not leave partial state behind.
+	assert.Equal(t, []string{"a"}, r.names())
+
+	assert.Panics(t, func() { Register("json", nil, JSON{}) })
+}
+
+func TestRegistryDecorated(t *testing.T) {
+	rdr := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(rdr))
+	meter := mp.Meter("test")
+	encoded, err := meter.Int64Counter("encoded")
+	require.NoError(t, err)
+	decoded, err := meter.Int64Counter("decoded")
+	require.NoError(t, err)
+
+	r := newRegistry()
+	require.NoError(t, r.register("dummy", []string{"application/dummy"}, RecordBytes(testCodec{}, encoded, decoded)))
+	c, ok := r.forContentType("application/dummy")
+	require.True(t, ok)
+
+	b, err := c.Encode(nil)
+	require.NoError(t, err)
+	require.NoError(t, c.Decode(b, nil))
+
+	var rm metricdata.ResourceMetrics
+	assert.NoError(t, rdr.Collect(context.Background(), &rm))
+	values := make(map[string]int64)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		values[m.Name] = m.Data.(metricdata.Sum[int64]).DataPoints[0].Value
+	}
+	assert.Equal(t, map[string]int64{"encoded": 5, "decoded": 5}, values)
+}
@@ -0,0 +1,286 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codec
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/elastic/apm-data/model/modelpb"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// sharedFields holds the metadata fields which MarshalSharedBatch factors
+// out of events into a shared header. The position of a field in this
+// slice determines its bit in the per-event inheritance mask, so fields
+// must only ever be appended.
+var sharedFields = []sharedField{
+	messageField(func(e *modelpb.APMEvent) **modelpb.Service { return &e.Service }),
+	messageField(func(e *modelpb.APMEvent) **modelpb.Agent { return &e.Agent }),
+	messageField(func(e *modelpb.APMEvent) **modelpb.Host { return &e.Host }),
+	messageField(func(e *modelpb.APMEvent) **modelpb.Cloud { return &e.Cloud }),
+	messageField(func(e *modelpb.APMEvent) **modelpb.Container { return &e.Container }),
+	messageField(func(e *modelpb.APMEvent) **modelpb.Kubernetes { return &e.Kubernetes }),
+}
+
+// MarshalSharedBatch encodes the events in b into a single byte slice,
+// factoring metadata shared by events out into a header.
+//
+// For each of the service, agent, host, cloud, container and kubernetes
+// fields, the most common value is written once to the header. Each event
+// is then written without the fields that match the header, along with
+// a mask recording which fields should be restored from the header by
+// UnmarshalSharedBatch.
+//
+// The encoded form is:
+//
+//	header_size:uvarint header:APMEvent
+//	(inherit_mask:uvarint event_size:uvarint event:APMEvent)*
+//
+// The events in b are not modified: events which inherit fields from the
+// header are encoded from shallow copies. Nil events are encoded as empty
+// events.
+func MarshalSharedBatch(b modelpb.Batch) ([]byte, error) {
+	header := &modelpb.APMEvent{}
+	keys := make([]string, len(b)*len(sharedFields))
+	for i, field := range sharedFields {
+		shared, err := field.mostCommon(b, keys[i*len(b):(i+1)*len(b)])
+		if err != nil {
+			return nil, err
+		}
+		if shared >= 0 {
+			field.copyRef(header, b[shared])
+		}
+	}
+
+	masks := make([]uint64, len(b))
+	for i, field := range sharedFields {
+		fieldKeys := keys[i*len(b) : (i+1)*len(b)]
+		headerKey, err := field.key(header)
+		if err != nil {
+			return nil, err
+		}
+		if headerKey == "" {
+			continue
+		}
+		for j := range b {
+			if fieldKeys[j] == headerKey {
+				masks[j] |= 1 << i
+			}
+		}
+	}
+
+	// Encode events which inherit fields from shallow copies with the
+	// inherited fields cleared, leaving the events in b untouched.
+	events := make([]*modelpb.APMEvent, len(b))
+	for j, event := range b {
+		events[j] = event
+		if masks[j] == 0 {
+			continue
+		}
+		events[j] = shallowCopy(event)
+		for i, field := range sharedFields {
+			if masks[j]&(1<<i) != 0 {
+				field.clear(events[j])
+			}
+		}
+	}
+
+	headerSize := header.SizeVT()
+	size := uvarintSize(uint64(headerSize)) + headerSize
+	for j, event := range events {
+		n := event.SizeVT()
+		size += uvarintSize(masks[j]) + uvarintSize(uint64(n)) + n
+	}
+	buf := make([]byte, size)
+	i := binary.PutUvarint(buf, uint64(headerSize))
+	if _, err := header.MarshalToSizedBufferVT(buf[i : i+headerSize]); err != nil {
+		return nil, fmt.Errorf("failed to encode header: %w", err)
+	}
+	i += headerSize
+	for j, event := range events {
+		n := event.SizeVT()
+		i += binary.PutUvarint(buf[i:], masks[j])
+		i += binary.PutUvarint(buf[i:], uint64(n))
+		if _, err := event.MarshalToSizedBufferVT(buf[i : i+n]); err != nil {
+			return nil, fmt.Errorf("failed to encode event %d: %w", j, err)
+		}
+		i += n
+	}
+	return buf, nil
+}
+
+// UnmarshalSharedBatch decodes events encoded by MarshalSharedBatch,
+// appending them to out. Fields inherited from the shared header are
+// cloned into each event, so events may be modified independently.
+func UnmarshalSharedBatch(data []byte, out *modelpb.Batch) error {
+	headerBytes, data, err := readSharedFrame(data)
+	if err != nil {
+		return fmt.Errorf("failed to read header: %w", err)
+	}
+	header := &modelpb.APMEvent{}
+	if err := header.UnmarshalVT(headerBytes); err != nil {
+		return fmt.Errorf("failed to decode header: %w", err)
+	}
+	for len(data) > 0 {
+		mask, n := binary.Uvarint(data)
+		if n <= 0 {
+			return fmt.Errorf("failed to read inherit mask: %w", io.ErrUnexpectedEOF)
+		}
+		if mask>>len(sharedFields) != 0 {
+			return fmt.Errorf("invalid inherit mask %#x", mask)
+		}
+		var eventBytes []byte
+		eventBytes, data, err = readSharedFrame(data[n:])
+		if err != nil {
+			return fmt.Errorf("failed to read event: %w", err)
+		}
+		event := &modelpb.APMEvent{}
+		if err := event.UnmarshalVT(eventBytes); err != nil {
+			return fmt.Errorf("failed to decode event: %w", err)
+		}
+		for i, field := range sharedFields {
+			if mask&(1<<i) != 0 {
+				field.copyClone(event, header)
+			}
+		}
+		*out = append(*out, event)
+	}
+	return nil
+}
+
+// shallowCopy returns a copy of e whose fields refer to the same values
+// as those of e.
+func shallowCopy(e *modelpb.APMEvent) *modelpb.APMEvent {
+	out := &modelpb.APMEvent{}
+	dst := out.ProtoReflect()
+	src := e.ProtoReflect()
+	src.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		dst.Set(fd, v)
+		return true
+	})
+	dst.SetUnknown(src.GetUnknown())
+	return out
+}
+
+// readSharedFrame reads a uvarint-prefixed frame from data, returning
+// the frame and the remaining data.
+func readSharedFrame(data []byte) ([]byte, []byte, error) {
+	size, n := binary.Uvarint(data)
+	if n == 0 {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	if n < 0 {
+		return nil, nil, errors.New("frame size overflows uint64")
+	}
+	data = data[n:]
+	if size > uint64(len(data)) {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	return data[:size], data[size:], nil
+}
+
+// sharedField provides access to an APMEvent field that may be shared
+// between events.
+type sharedField interface {
+	// key returns the encoding of the field in e, or "" if it is unset.
+	key(e *modelpb.APMEvent) (string, error)
+
+	// mostCommon records the key of each event's field in keys, and
+	// returns the index of an event with the most common value, or -1
+	// if no value occurs more than once.
+	mostCommon(b modelpb.Batch, keys []string) (int, error)
+
+	// clear clears the field in e.
+	clear(e *modelpb.APMEvent)
+
+	// copyRef sets the field in dst to the field in src.
+	copyRef(dst, src *modelpb.APMEvent)
+
+	// copyClone sets the field in dst to a clone of the field in src.
+	copyClone(dst, src *modelpb.APMEvent)
+}
+
+type vtprotoClonedMessage[T any] interface {
+	*T
+	MarshalVT() ([]byte, error)
+	CloneVT() *T
+}
+
+// messageField returns a sharedField for the message field pointed to
+// by the result of f.
+func messageField[T any, P vtprotoClonedMessage[T]](f func(*modelpb.APMEvent) *P) sharedField {
+	return sharedMessageField[T, P](f)
+}
+
+// sharedMessageField implements sharedField for a message field, given
+// a function returning a pointer to the field.
+type sharedMessageField[T any, P vtprotoClonedMessage[T]] func(*modelpb.APMEvent) *P
+
+func (f sharedMessageField[T, P]) key(e *modelpb.APMEvent) (string, error) {
+	if e == nil || *f(e) == nil {
+		return "", nil
+	}
+	b, err := (*f(e)).MarshalVT()
+	if err != nil {
+		return "", err
+	}
+	// Prefix the key so that empty messages differ from unset fields.
+	return "\x00" + string(b), nil
+}
+
+func (f sharedMessageField[T, P]) mostCommon(b modelpb.Batch, keys []string) (int, error) {
+	type count struct{ first, n int }
+	counts := make(map[string]*count)
+	best := -1
+	bestCount := 1
+	for j, event := range b {
+		key, err := f.key(event)
+		if err != nil {
+			return -1, err
+		}
+		keys[j] = key
+		if key == "" {
+			continue
+		}
+		c, ok := counts[key]
+		if !ok {
+			c = &count{first: j}
+			counts[key] = c
+		}
+		c.n++
+		if c.n > bestCount {
+			best, bestCount = c.first, c.n
+		}
+	}
+	return best, nil
+}
+
+func (f sharedMessageField[T, P]) clear(e *modelpb.APMEvent) {
+	*f(e) = nil
+}
+
+func (f sharedMessageField[T, P]) copyRef(dst, src *modelpb.APMEvent) {
+	*f(dst) = *f(src)
+}
+
+func (f sharedMessageField[T, P]) copyClone(dst, src *modelpb.APMEvent) {
+	*f(dst) = P((*f(src)).CloneVT())
+}
@@ -0,0 +1,145 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codec
+
+import (
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/elastic/apm-data/model/modelpb"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestSharedBatchRoundTrip(t *testing.T) {
+	other := fullEvent(t)
+	other.Service.Name = "other"
+	other.Host = nil
+	other.Cloud = &modelpb.Cloud{}
+
+	batch := modelpb.Batch{
+		fullEvent(t),
+		fullEvent(t),
+		other,
+		{Message: "no metadata"},
+		{},
+		fullEvent(t),
+	}
+	orig := make(modelpb.Batch, len(batch))
+	for i, event := range batch {
+		orig[i] = proto.Clone(event).(*modelpb.APMEvent)
+	}
+
+	b, err := MarshalSharedBatch(batch)
+	require.NoError(t, err)
+	assert.Empty(t, cmp.Diff(orig, batch, protocmp.Transform()), "events must not be modified by encoding")
+
+	var decoded modelpb.Batch
+	require.NoError(t, UnmarshalSharedBatch(b, &decoded))
+	assert.Empty(t, cmp.Diff(batch, decoded, protocmp.Transform()))
+
+	// Inherited fields are cloned, not shared between events.
+	decoded[0].Service.Name = "modified"
+	assert.Equal(t, batch[1].Service.Name, decoded[1].Service.Name)
+
+	plain, err := MarshalBatch(batch)
+	require.NoError(t, err)
+	assert.Less(t, len(b), len(plain))
+}
+
+func TestSharedBatchConcurrentEncode(t *testing.T) {
+	batch := modelpb.Batch{fullEvent(t), fullEvent(t), fullEvent(t)}
+
+	// Encoding must not write to the events, so concurrent encoding and
+	// reading of the same events is safe; run with -race to check.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := MarshalSharedBatch(batch)
+			assert.NoError(t, err)
+			assert.NotNil(t, batch[0].Service)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSharedBatchEmpty(t *testing.T) {
+	b, err := MarshalSharedBatch(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0}, b)
+
+	var decoded modelpb.Batch
+	require.NoError(t, UnmarshalSharedBatch(b, &decoded))
+	assert.Empty(t, decoded)
+}
+
+func TestSharedBatchTruncated(t *testing.T) {
+	b, err := MarshalSharedBatch(modelpb.Batch{fullEvent(t), fullEvent(t)})
+	require.NoError(t, err)
+
+	var decoded modelpb.Batch
+	for _, n := range []int{0, 1, len(b) / 2, len(b) - 1} {
+		err := UnmarshalSharedBatch(b[:n], &decoded)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF, "length %d", n)
+	}
+
+	err = UnmarshalSharedBatch([]byte{0, 0x80, 0x01, 0}, &decoded)
+	assert.EqualError(t, err, "invalid inherit mask 0x80")
+}
+
+func BenchmarkSharedBatch(b *testing.B) {
+	batch := make(modelpb.Batch, 100)
+	for i := range batch {
+		batch[i] = fullEvent(b)
+	}
+	encoded, err := MarshalSharedBatch(batch)
+	require.NoError(b, err)
+
+	b.Run("encode/format=shared", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			MarshalSharedBatch(batch)
+		}
+		b.ReportMetric(float64(len(encoded)), "bytes/op")
+	})
+	b.Run("encode/format=vtproto", func(b *testing.B) {
+		b.ReportAllocs()
+		var size int
+		for i := 0; i < b.N; i++ {
+			size = 0
+			for _, event := range batch {
+				encoded, _ := event.MarshalVT()
+				size += len(encoded)
+			}
+		}
+		b.ReportMetric(float64(size), "bytes/op")
+	})
+	b.Run("decode/format=shared", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var decoded modelpb.Batch
+			UnmarshalSharedBatch(encoded, &decoded)
+		}
+	})
+}
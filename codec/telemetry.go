@@ -0,0 +1,159 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codec
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/elastic/apm-data/model/modelpb"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// TelemetryConfig holds configuration for Instrument, InstrumentEncoder
+// and InstrumentDecoder. Any of the metrics may be nil, in which case
+// they are not recorded.
+type TelemetryConfig struct {
+	// EncodedBytes records the number of bytes that have been encoded.
+	EncodedBytes metric.Int64Counter
+
+	// DecodedBytes records the number of bytes that have been decoded.
+	DecodedBytes metric.Int64Counter
+
+	// EncodeDuration records the time taken to encode, in seconds.
+	EncodeDuration metric.Float64Histogram
+
+	// DecodeDuration records the time taken to decode, in seconds.
+	DecodeDuration metric.Float64Histogram
+
+	// EncodeErrors records the number of encoding errors. Errors are
+	// recorded with a "message.type" attribute, holding the Go type of
+	// the message that failed to encode.
+	EncodeErrors metric.Int64Counter
+
+	// DecodeErrors records the number of decoding errors. Errors are
+	// recorded with a "message.type" attribute, holding the Go type of
+	// the message that failed to decode.
+	DecodeErrors metric.Int64Counter
+
+	// Attributes are recorded with every measurement, for example to
+	// identify the codec with attribute.String("codec", "vtproto").
+	Attributes []attribute.KeyValue
+
+	// MessageAttributes, if non-nil, returns additional attributes to
+	// record for the given message. Decoded messages are passed to
+	// MessageAttributes after decoding. See ProcessorEventAttributes.
+	MessageAttributes func(msg any) []attribute.KeyValue
+}
+
+// ProcessorEventAttributes returns a "processor.event" attribute for
+// *modelpb.APMEvent messages with a processor, and nil otherwise.
+// It is intended for use as TelemetryConfig.MessageAttributes.
+func ProcessorEventAttributes(msg any) []attribute.KeyValue {
+	if event, ok := msg.(*modelpb.APMEvent); ok && event.GetProcessor().GetEvent() != "" {
+		return []attribute.KeyValue{attribute.String("processor.event", event.Processor.Event)}
+	}
+	return nil
+}
+
+// Instrument decorates a codec with the metrics in cfg.
+func Instrument(c Codec, cfg TelemetryConfig) Codec {
+	return metricsRecorder{encoder: c, decoder: c, cfg: cfg}
+}
+
+// InstrumentEncoder decorates an encoder with the encoding metrics in cfg.
+func InstrumentEncoder(e Encoder, cfg TelemetryConfig) Encoder {
+	return metricsRecorder{encoder: e, cfg: cfg}
+}
+
+// InstrumentDecoder decorates a decoder with the decoding metrics in cfg.
+func InstrumentDecoder(d Decoder, cfg TelemetryConfig) Decoder {
+	return metricsRecorder{decoder: d, cfg: cfg}
+}
+
+type metricsRecorder struct {
+	encoder Encoder
+	decoder Decoder
+	cfg     TelemetryConfig
+}
+
+// Encode encodes a type into its byte slice representation.
+func (m metricsRecorder) Encode(in any) ([]byte, error) {
+	return m.EncodeContext(context.Background(), in)
+}
+
+// EncodeContext encodes a type into its byte slice representation,
+// recording metrics with the given context.
+func (m metricsRecorder) EncodeContext(ctx context.Context, in any) ([]byte, error) {
+	start := time.Now()
+	b, err := EncodeContext(ctx, m.encoder, in)
+	m.record(ctx, in, len(b), start, err, m.cfg.EncodedBytes, m.cfg.EncodeDuration, m.cfg.EncodeErrors)
+	return b, err
+}
+
+// AppendEncode appends the encoded representation of a type to dst, and
+// records the number of bytes appended.
+func (m metricsRecorder) AppendEncode(dst []byte, in any) ([]byte, error) {
+	start := time.Now()
+	b, err := AppendEncode(m.encoder, dst, in)
+	m.record(context.Background(), in, len(b)-len(dst), start, err, m.cfg.EncodedBytes, m.cfg.EncodeDuration, m.cfg.EncodeErrors)
+	return b, err
+}
+
+// Decode decodes a byte slice representation into its Go type.
+func (m metricsRecorder) Decode(in []byte, out any) error {
+	return m.DecodeContext(context.Background(), in, out)
+}
+
+// DecodeContext decodes a byte slice representation into its Go type,
+// recording metrics with the given context.
+func (m metricsRecorder) DecodeContext(ctx context.Context, in []byte, out any) error {
+	start := time.Now()
+	err := DecodeContext(ctx, m.decoder, in, out)
+	m.record(ctx, out, len(in), start, err, m.cfg.DecodedBytes, m.cfg.DecodeDuration, m.cfg.DecodeErrors)
+	return err
+}
+
+func (m metricsRecorder) record(
+	ctx context.Context,
+	msg any, n int, start time.Time, err error,
+	bytes metric.Int64Counter,
+	duration metric.Float64Histogram,
+	errors metric.Int64Counter,
+) {
+	if bytes == nil && duration == nil && (errors == nil || err == nil) {
+		return
+	}
+	attrs := m.cfg.Attributes
+	if m.cfg.MessageAttributes != nil {
+		attrs = append(attrs[:len(attrs):len(attrs)], m.cfg.MessageAttributes(msg)...)
+	}
+	opt := metric.WithAttributes(attrs...)
+	if bytes != nil {
+		bytes.Add(ctx, int64(n), opt)
+	}
+	if duration != nil {
+		duration.Record(ctx, time.Since(start).Seconds(), opt)
+	}
+	if errors != nil && err != nil {
+		errAttrs := append(attrs[:len(attrs):len(attrs)], attribute.String("message.type", fmt.Sprintf("%T", msg)))
+		errors.Add(ctx, 1, metric.WithAttributes(errAttrs...))
+	}
+}
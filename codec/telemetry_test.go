@@ -0,0 +1,146 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codec
+
+import (
+	"context"
+	"testing"
+
+	"github.com/elastic/apm-data/model/modelpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+)
+
+func TestInstrument(t *testing.T) {
+	rdr := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(rdr))
+	meter := mp.Meter("test")
+
+	var cfg TelemetryConfig
+	var err error
+	cfg.EncodedBytes, err = meter.Int64Counter("encoded")
+	require.NoError(t, err)
+	cfg.DecodedBytes, err = meter.Int64Counter("decoded")
+	require.NoError(t, err)
+	cfg.EncodeDuration, err = meter.Float64Histogram("encode.duration")
+	require.NoError(t, err)
+	cfg.DecodeDuration, err = meter.Float64Histogram("decode.duration")
+	require.NoError(t, err)
+	cfg.EncodeErrors, err = meter.Int64Counter("encode.errors")
+	require.NoError(t, err)
+	cfg.DecodeErrors, err = meter.Int64Counter("decode.errors")
+	require.NoError(t, err)
+	cfg.Attributes = []attribute.KeyValue{attribute.String("codec", "vtproto")}
+	cfg.MessageAttributes = ProcessorEventAttributes
+
+	c := Instrument(VTProto{}, cfg)
+	ev := &modelpb.APMEvent{Processor: modelpb.TransactionProcessor()}
+	b, err := EncodeContext(context.Background(), c, ev)
+	require.NoError(t, err)
+	require.NoError(t, DecodeContext(context.Background(), c, b, &modelpb.APMEvent{}))
+
+	_, err = c.Encode("invalid")
+	require.Error(t, err)
+	require.Error(t, c.Decode([]byte{0xff}, &modelpb.APMEvent{}))
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, rdr.Collect(context.Background(), &rm))
+	metrics := make(map[string]metricdata.Metrics)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		metrics[m.Name] = m
+	}
+
+	transactionAttrs := attribute.NewSet(
+		attribute.String("codec", "vtproto"),
+		attribute.String("processor.event", "transaction"),
+	)
+	codecAttrs := attribute.NewSet(attribute.String("codec", "vtproto"))
+	sum := func(dps ...metricdata.DataPoint[int64]) metricdata.Sum[int64] {
+		return metricdata.Sum[int64]{
+			Temporality: metricdata.CumulativeTemporality,
+			IsMonotonic: true,
+			DataPoints:  dps,
+		}
+	}
+	metricdatatest.AssertAggregationsEqual(t, sum(
+		metricdata.DataPoint[int64]{Attributes: transactionAttrs, Value: int64(len(b))},
+		metricdata.DataPoint[int64]{Attributes: codecAttrs, Value: 0},
+	), metrics["encoded"].Data, metricdatatest.IgnoreTimestamp())
+	metricdatatest.AssertAggregationsEqual(t, sum(
+		metricdata.DataPoint[int64]{Attributes: transactionAttrs, Value: int64(len(b))},
+		metricdata.DataPoint[int64]{Attributes: codecAttrs, Value: 1},
+	), metrics["decoded"].Data, metricdatatest.IgnoreTimestamp())
+	metricdatatest.AssertAggregationsEqual(t, sum(
+		metricdata.DataPoint[int64]{Attributes: attribute.NewSet(
+			attribute.String("codec", "vtproto"),
+			attribute.String("message.type", "string"),
+		), Value: 1},
+	), metrics["encode.errors"].Data, metricdatatest.IgnoreTimestamp())
+	metricdatatest.AssertAggregationsEqual(t, sum(
+		metricdata.DataPoint[int64]{Attributes: attribute.NewSet(
+			attribute.String("codec", "vtproto"),
+			attribute.String("message.type", "*modelpb.APMEvent"),
+		), Value: 1},
+	), metrics["decode.errors"].Data, metricdatatest.IgnoreTimestamp())
+
+	for _, name := range []string{"encode.duration", "decode.duration"} {
+		var count uint64
+		for _, dp := range metrics[name].Data.(metricdata.Histogram[float64]).DataPoints {
+			count += dp.Count
+		}
+		assert.Equal(t, uint64(2), count, name)
+	}
+}
+
+func TestInstrumentContext(t *testing.T) {
+	ctx := context.WithValue(context.Background(), testContextKey{}, "value")
+
+	inner := &contextCodec{}
+	c := Instrument(inner, TelemetryConfig{})
+	_, err := EncodeContext(ctx, c, nil)
+	require.NoError(t, err)
+	require.NoError(t, DecodeContext(ctx, c, nil, nil))
+	assert.Equal(t, []any{"value", "value"}, inner.values)
+
+	inner.values = nil
+	_, err = c.Encode(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []any{nil}, inner.values)
+}
+
+type testContextKey struct{}
+
+// contextCodec records the testContextKey values of contexts passed to it.
+type contextCodec struct {
+	testCodec
+	values []any
+}
+
+func (c *contextCodec) EncodeContext(ctx context.Context, in any) ([]byte, error) {
+	c.values = append(c.values, ctx.Value(testContextKey{}))
+	return c.Encode(in)
+}
+
+func (c *contextCodec) DecodeContext(ctx context.Context, in []byte, out any) error {
+	c.values = append(c.values, ctx.Value(testContextKey{}))
+	return c.Decode(in, out)
+}
@@ -0,0 +1,141 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codec
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/elastic/apm-data/model/modelpb"
+)
+
+// versionMagic is the first byte of the header written by versioned
+// encoders. Like compressionMagic, it cannot be the first byte of a
+// protobuf message or a JSON document.
+const versionMagic = 0x01
+
+// Migration migrates an event from one schema version to the next.
+type Migration func(*modelpb.APMEvent) error
+
+// VersionedConfig holds configuration for Versioned.
+type VersionedConfig struct {
+	// Version is the current schema version. Encoded payloads are
+	// prefixed with this version, and decoded payloads are migrated
+	// to this version.
+	Version uint32
+
+	// Migrations holds, for a schema version, the function that migrates
+	// events decoded at that version to the following version. Versions
+	// without a migration are assumed to be compatible with the next.
+	Migrations map[uint32]Migration
+}
+
+// UnknownVersionError is returned when decoding a payload with a schema
+// version newer than the current version.
+type UnknownVersionError struct {
+	// Version is the schema version of the payload.
+	Version uint32
+
+	// Current is the current schema version.
+	Current uint32
+}
+
+// Error returns the error message.
+func (e *UnknownVersionError) Error() string {
+	return fmt.Sprintf("unknown schema version %d (current version is %d)", e.Version, e.Current)
+}
+
+// Versioned decorates a codec, prefixing encoded payloads with a schema
+// version, and migrating decoded events to the current schema version.
+//
+// Payloads without a version prefix, such as those encoded before the
+// codec was introduced, are treated as schema version zero. Payloads
+// with a version greater than cfg.Version fail to decode with an
+// *UnknownVersionError.
+func Versioned(c Codec, cfg VersionedConfig) Codec {
+	return versioned{codec: c, cfg: cfg}
+}
+
+type versioned struct {
+	codec Codec
+	cfg   VersionedConfig
+}
+
+// Encode encodes a type into its byte slice representation, prefixed
+// with the current schema version.
+func (v versioned) Encode(in any) ([]byte, error) {
+	b, err := v.AppendEncode(nil, in)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// AppendEncode appends the byte slice representation of a type to dst,
+// prefixed with the current schema version.
+func (v versioned) AppendEncode(dst []byte, in any) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, versionMagic)
+	dst = binary.AppendUvarint(dst, uint64(v.cfg.Version))
+	b, err := AppendEncode(v.codec, dst, in)
+	if err != nil {
+		return dst[:n], err
+	}
+	return b, nil
+}
+
+// Decode decodes a byte slice representation into its Go type, and
+// migrates it to the current schema version.
+func (v versioned) Decode(in []byte, out any) error {
+	version, in, err := readVersion(in)
+	if err != nil {
+		return err
+	}
+	if version > v.cfg.Version {
+		return &UnknownVersionError{Version: version, Current: v.cfg.Version}
+	}
+	if err := v.codec.Decode(in, out); err != nil {
+		return err
+	}
+	for ; version < v.cfg.Version; version++ {
+		migrate, ok := v.cfg.Migrations[version]
+		if !ok {
+			continue
+		}
+		event, ok := out.(*modelpb.APMEvent)
+		if !ok {
+			return fmt.Errorf("failed to migrate from schema version %d, message is %T (not a *modelpb.APMEvent)", version, out)
+		}
+		if err := migrate(event); err != nil {
+			return fmt.Errorf("failed to migrate from schema version %d: %w", version, err)
+		}
+	}
+	return nil
+}
+
+func readVersion(in []byte) (uint32, []byte, error) {
+	if len(in) == 0 || in[0] != versionMagic {
+		return 0, in, nil
+	}
+	version, n := binary.Uvarint(in[1:])
+	if n <= 0 || version > uint64(^uint32(0)) {
+		return 0, nil, errors.New("invalid schema version header")
+	}
+	return uint32(version), in[1+n:], nil
+}
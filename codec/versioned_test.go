@@ -0,0 +1,135 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codec
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/elastic/apm-data/model/modelpb"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestVersionedRoundTrip(t *testing.T) {
+	ev := fullEvent(t)
+	for name, c := range map[string]Codec{
+		"json":      ProtoJSON{},
+		"vtproto":   VTProto{},
+		"compress":  compressCodec(t, VTProto{}),
+		"versioned": Versioned(VTProto{}, VersionedConfig{Version: 7}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			codec := Versioned(c, VersionedConfig{Version: 300})
+			b, err := codec.Encode(ev)
+			require.NoError(t, err)
+			assert.Equal(t, []byte{versionMagic, 0xac, 0x02}, b[:3])
+
+			out := &modelpb.APMEvent{}
+			require.NoError(t, codec.Decode(b, out))
+			assert.Empty(t, cmp.Diff(ev, out, protocmp.Transform()))
+		})
+	}
+}
+
+func TestVersionedMigrations(t *testing.T) {
+	var migrated []uint32
+	migrations := map[uint32]Migration{
+		0: func(event *modelpb.APMEvent) error {
+			// Move a deprecated label to its replacement field.
+			migrated = append(migrated, 0)
+			event.Message = event.Labels["deprecated_message"].GetValue()
+			delete(event.Labels, "deprecated_message")
+			return nil
+		},
+		2: func(event *modelpb.APMEvent) error {
+			migrated = append(migrated, 2)
+			event.Message += "!"
+			return nil
+		},
+	}
+	v0 := &modelpb.APMEvent{Labels: modelpb.Labels{
+		"deprecated_message": {Value: "hello"},
+	}}
+
+	// Unversioned payloads are treated as version zero.
+	unversioned, err := VTProto{}.Encode(v0)
+	require.NoError(t, err)
+	versionZero, err := Versioned(VTProto{}, VersionedConfig{}).Encode(v0)
+	require.NoError(t, err)
+
+	codec := Versioned(VTProto{}, VersionedConfig{Version: 3, Migrations: migrations})
+	for _, b := range [][]byte{unversioned, versionZero} {
+		migrated = nil
+		out := &modelpb.APMEvent{}
+		require.NoError(t, codec.Decode(b, out))
+		assert.Equal(t, []uint32{0, 2}, migrated)
+		assert.Empty(t, cmp.Diff(&modelpb.APMEvent{Message: "hello!"}, out, protocmp.Transform()))
+	}
+
+	// Payloads at intermediate versions only run later migrations.
+	v2, err := Versioned(VTProto{}, VersionedConfig{Version: 2}).Encode(&modelpb.APMEvent{Message: "hi"})
+	require.NoError(t, err)
+	migrated = nil
+	out := &modelpb.APMEvent{}
+	require.NoError(t, codec.Decode(v2, out))
+	assert.Equal(t, []uint32{2}, migrated)
+	assert.Equal(t, "hi!", out.Message)
+}
+
+func TestVersionedErrors(t *testing.T) {
+	codec := Versioned(VTProto{}, VersionedConfig{Version: 1, Migrations: map[uint32]Migration{
+		0: func(*modelpb.APMEvent) error { return errors.New("boom") },
+	}})
+
+	v2, err := Versioned(VTProto{}, VersionedConfig{Version: 2}).Encode(&modelpb.APMEvent{})
+	require.NoError(t, err)
+	err = codec.Decode(v2, &modelpb.APMEvent{})
+	var versionErr *UnknownVersionError
+	require.ErrorAs(t, err, &versionErr)
+	assert.Equal(t, &UnknownVersionError{Version: 2, Current: 1}, versionErr)
+	assert.EqualError(t, err, "unknown schema version 2 (current version is 1)")
+
+	v0, err := VTProto{}.Encode(&modelpb.APMEvent{})
+	require.NoError(t, err)
+	err = codec.Decode(v0, &modelpb.APMEvent{})
+	assert.EqualError(t, err, "failed to migrate from schema version 0: boom")
+
+	err = Versioned(JSON{}, VersionedConfig{Version: 1, Migrations: map[uint32]Migration{
+		0: func(*modelpb.APMEvent) error { return nil },
+	}}).Decode([]byte(`{}`), &map[string]any{})
+	assert.EqualError(t, err, "failed to migrate from schema version 0, message is *map[string]interface {} (not a *modelpb.APMEvent)")
+
+	err = codec.Decode([]byte{versionMagic, 0xff}, &modelpb.APMEvent{})
+	assert.EqualError(t, err, "invalid schema version header")
+
+	b, err := codec.Encode("invalid")
+	assert.Error(t, err)
+	assert.Nil(t, b)
+}
+
+func compressCodec(t testing.TB, c Codec) Codec {
+	encoder, err := CompressEncoder(c, CompressionConfig{Algorithm: Gzip})
+	require.NoError(t, err)
+	return struct {
+		Encoder
+		Decoder
+	}{encoder, DecompressDecoder(c, CompressionConfig{})}
+}
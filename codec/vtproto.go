@@ -17,7 +17,10 @@
 
 package codec
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+)
 
 // vtprotoMessage expects a struct to have MarshalVT() and UnmarshalVT() methods
 // that coverts data between byte slices and the desired format
@@ -26,6 +29,13 @@ type vtprotoMessage interface {
 	UnmarshalVT([]byte) error
 }
 
+// vtprotoSizedMessage expects a struct to have SizeVT() and
+// MarshalToSizedBufferVT() methods, for marshaling into an existing buffer.
+type vtprotoSizedMessage interface {
+	SizeVT() int
+	MarshalToSizedBufferVT([]byte) (int, error)
+}
+
 // VTProto is a composite of Encoder and Decoder
 type VTProto struct{}
 
@@ -38,6 +48,32 @@ func (v VTProto) Encode(in any) ([]byte, error) {
 	return vt.MarshalVT()
 }
 
+// AppendEncode appends the encoding of a vtprotoSizedMessage type to dst.
+// No allocation is performed if dst has sufficient capacity.
+func (v VTProto) AppendEncode(dst []byte, in any) ([]byte, error) {
+	vt, ok := in.(vtprotoSizedMessage)
+	if !ok {
+		return dst, fmt.Errorf("failed to encode, message is %T (missing vtprotobuf helpers)", in)
+	}
+	n, size := len(dst), vt.SizeVT()
+	if cap(dst)-n < size {
+		grown := make([]byte, n, n+size)
+		copy(grown, dst)
+		dst = grown
+	}
+	dst = dst[:n+size]
+	if _, err := vt.MarshalToSizedBufferVT(dst[n:]); err != nil {
+		return dst[:n], err
+	}
+	return dst, nil
+}
+
+// EncodeTo writes the encoding of a vtprotoSizedMessage type to w, using
+// a pooled buffer.
+func (v VTProto) EncodeTo(w io.Writer, in any) error {
+	return encodeToPooled(v, w, in)
+}
+
 // Decode decodes a byte slice into vtprotoMessage type.
 func (v VTProto) Decode(in []byte, out any) error {
 	vt, ok := out.(vtprotoMessage)
@@ -241,6 +241,7 @@ func TestDecodeMapToTransactionModel(t *testing.T) {
 				"ChildIDs",
 				"composite",
 				"db",
+				"gen_ai",
 				"message",
 				"representative_count",
 				"stacktrace.library_frame",
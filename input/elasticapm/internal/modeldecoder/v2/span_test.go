@@ -113,6 +113,9 @@ func TestDecodeMapToSpanModel(t *testing.T) {
 				// Derived using service.target.*
 				"destination_service.Resource",
 
+				// Only set for OpenTelemetry spans:
+				"gen_ai",
+
 				// Not set for spans:
 				"destination_service.response_time",
 				"destination_service.ResponseTime.Count",
@@ -0,0 +1,221 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package otlp
+
+import (
+	"context"
+
+	"github.com/elastic/apm-data/input"
+	"github.com/elastic/apm-data/model/modelpb"
+	"go.uber.org/zap"
+)
+
+// batchSplitter accumulates the events converted from an OTLP payload,
+// and passes them to the processor in batches bounded by the configured
+// MaxBatchSize and MaxBatchBytes.
+//
+// Converters append events to batch, and call boundary at the end of
+// each resource and scope; full batches are only processed at these
+// boundaries. If no limits are configured, all events are processed
+// in a single batch by close.
+//
+// Converters also call mark after appending the events for one or more
+// OTLP items (spans, log records, or data points). The items are counted
+// as accepted once all events appended before the mark are processed.
+//
+// The batchSplitter owns the semaphore token acquired for the payload,
+// which is released by release, or in async mode once the batches have
+// been processed in the background.
+type batchSplitter struct {
+	ctx       context.Context
+	processor modelpb.BatchProcessor
+	logger    *zap.Logger
+	sem       input.Semaphore
+	maxEvents int
+	maxBytes  int
+	async     bool
+
+	batch     modelpb.Batch
+	sizes     []int           // encoded sizes of events in batch, computed lazily
+	marks     []batchMark     // item marks, ordered by end
+	pending   []modelpb.Batch // full batches awaiting async processing
+	processed bool
+	accepted  int64
+	err       error
+}
+
+// batchMark records that items are complete once the first end events
+// of batch have been processed.
+type batchMark struct {
+	end   int
+	items int64
+}
+
+// newBatchSplitter returns a new batchSplitter. The semaphore must have
+// been acquired, and release must be called once the payload is consumed.
+func (c *Consumer) newBatchSplitter(ctx context.Context) *batchSplitter {
+	return &batchSplitter{
+		ctx:       ctx,
+		processor: c.config.Processor,
+		logger:    c.config.Logger,
+		sem:       c.sem,
+		maxEvents: c.config.MaxBatchSize,
+		maxBytes:  c.config.MaxBatchBytes,
+		async:     c.config.Async,
+	}
+}
+
+// release releases the semaphore, unless it has been handed over to
+// background processing.
+func (s *batchSplitter) release() {
+	if s.sem != nil {
+		s.sem.Release(1)
+		s.sem = nil
+	}
+}
+
+// mark records that the events for n items have been appended to batch.
+func (s *batchSplitter) mark(n int64) {
+	if n == 0 {
+		return
+	}
+	if len(s.marks) > 0 && s.marks[len(s.marks)-1].end == len(s.batch) {
+		s.marks[len(s.marks)-1].items += n
+		return
+	}
+	s.marks = append(s.marks, batchMark{end: len(s.batch), items: n})
+}
+
+// boundary processes full batches of the events accumulated so far.
+//
+// Once processing has failed, accumulated events are discarded.
+func (s *batchSplitter) boundary() {
+	if s.err != nil {
+		s.batch, s.sizes, s.marks = s.batch[:0], s.sizes[:0], s.marks[:0]
+		return
+	}
+	if s.maxEvents <= 0 && s.maxBytes <= 0 {
+		return
+	}
+	for s.err == nil {
+		n := s.fullBatchLen()
+		if n == 0 {
+			break
+		}
+		s.process(n)
+	}
+}
+
+// fullBatchLen returns the number of leading events in batch which form
+// a full batch, or zero if the accumulated events do not fill a batch.
+func (s *batchSplitter) fullBatchLen() int {
+	var bytes int
+	for i, event := range s.batch {
+		if s.maxEvents > 0 && i == s.maxEvents {
+			return i
+		}
+		if s.maxBytes > 0 {
+			if i == len(s.sizes) {
+				s.sizes = append(s.sizes, event.SizeVT())
+			}
+			bytes += s.sizes[i]
+			if bytes > s.maxBytes {
+				if i == 0 {
+					// A batch holds at least one event.
+					return 1
+				}
+				return i
+			}
+		}
+	}
+	if s.maxEvents > 0 && len(s.batch) == s.maxEvents {
+		return len(s.batch)
+	}
+	return 0
+}
+
+// process passes the first n events of batch to the processor,
+// removing them from batch.
+func (s *batchSplitter) process(n int) {
+	var batch modelpb.Batch
+	if n == len(s.batch) {
+		batch, s.batch, s.sizes = s.batch, nil, s.sizes[:0]
+	} else {
+		// The processor may retain the batch, so give it a copy.
+		batch = make(modelpb.Batch, n)
+		copy(batch, s.batch)
+		s.batch = s.batch[:copy(s.batch, s.batch[n:])]
+		if len(s.sizes) > n {
+			s.sizes = s.sizes[:copy(s.sizes, s.sizes[n:])]
+		} else {
+			s.sizes = s.sizes[:0]
+		}
+	}
+	var items int64
+	var i int
+	for ; i < len(s.marks) && s.marks[i].end <= n; i++ {
+		items += s.marks[i].items
+	}
+	s.marks = s.marks[:copy(s.marks, s.marks[i:])]
+	for i := range s.marks {
+		s.marks[i].end -= n
+	}
+
+	s.processed = true
+	if s.async {
+		s.pending = append(s.pending, batch)
+		s.accepted += items
+		return
+	}
+	if s.err = s.processor.ProcessBatch(s.ctx, &batch); s.err == nil {
+		s.accepted += items
+	}
+}
+
+// close processes the remaining events, returning the first error
+// returned by the processor. After close returns, accepted holds the
+// number of items in the batches processed successfully.
+//
+// In async mode, close instead starts processing the batches in the
+// background, and takes over releasing the semaphore.
+func (s *batchSplitter) close() error {
+	s.boundary()
+	if s.err == nil && (len(s.batch) > 0 || !s.processed) {
+		// Payloads with no events are processed as an empty batch.
+		s.process(len(s.batch))
+	}
+	if s.async {
+		sem, pending := s.sem, s.pending
+		s.sem, s.pending = nil, nil
+		go func() {
+			defer sem.Release(1)
+			for i := range pending {
+				// Batches are independent, so a failure does not
+				// prevent the remaining batches from being processed.
+				if err := s.processor.ProcessBatch(s.ctx, &pending[i]); err != nil {
+					s.logger.Error(
+						"failed handling async request",
+						zap.Error(err),
+						zap.Int("events", len(pending[i])),
+					)
+				}
+			}
+		}()
+	}
+	return s.err
+}
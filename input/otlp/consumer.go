@@ -18,7 +18,10 @@
 package otlp
 
 import (
+	"context"
+	"errors"
 	"sync/atomic"
+	"time"
 
 	"github.com/elastic/apm-data/input"
 	"github.com/elastic/apm-data/model/modelpb"
@@ -26,6 +29,10 @@ import (
 	"go.uber.org/zap"
 )
 
+// ErrQueueFull is returned by the Consume methods when Async is configured
+// and the semaphore is full.
+var ErrQueueFull = errors.New("queue is full")
+
 // ConsumerConfig holds configuration for Consumer.
 type ConsumerConfig struct {
 	// Logger holds a logger for the consumer. If this is nil, then
@@ -39,14 +46,82 @@ type ConsumerConfig struct {
 	// Semaphore holds a semaphore on which Processor.HandleStream will acquire a
 	// token before proceeding, to limit concurrency.
 	Semaphore input.Semaphore
+
+	// SumTemporality, if specified, holds the aggregation temporality to
+	// which monotonic sum metrics are converted. By default, and always for
+	// non-monotonic sums, sums are recorded with the temporality they are
+	// received with.
+	//
+	// Conversion is stateful: the consumer tracks each time series, keyed
+	// by resource, scope, metric name and attributes, to compute deltas
+	// from cumulative sums and vice versa. Conversion therefore requires
+	// all data points for a time series to be sent to the same consumer.
+	// When converting to deltas, the first cumulative data point of a series
+	// is dropped unless the series started after the consumer was created;
+	// such data points are counted as accepted, not rejected. Data points
+	// not newer than the previous data point of their series are rejected.
+	SumTemporality modelpb.AggregationTemporality
+
+	// SumStateTTL holds the duration after which the state of a time
+	// series not seen is discarded, when SumTemporality is specified.
+	// If this is zero, a default of 10 minutes is used.
+	SumStateTTL time.Duration
+
+	// NormalizeMetricUnits, if true, converts the values of metrics with
+	// known units to a common unit for their dimension: durations are
+	// converted to milliseconds, sizes to bytes, and percentages to ratios.
+	// By default, values are recorded as received, with their units
+	// translated to the Elastic equivalent where one exists.
+	NormalizeMetricUnits bool
+
+	// LogMapBody controls how log record bodies of type map are
+	// recorded. By default, the body is recorded as the event message,
+	// and its entries are also recorded as labels.
+	LogMapBody LogMapBodyPolicy
+
+	// MaxBatchSize and MaxBatchBytes bound the batches passed to
+	// Processor. If either is positive, events are converted and processed
+	// incrementally, with full batches processed at resource and scope
+	// boundaries. By default each payload is processed as a single batch.
+	//
+	// If processing a batch fails, the remaining events in the payload
+	// are discarded, and the error is returned along with a result which
+	// counts the items in batches processed before the failure; those
+	// batches are not rolled back.
+	//
+	// MaxBatchSize, if positive, holds the maximum number of events in
+	// each batch.
+	MaxBatchSize int
+
+	// MaxBatchBytes, if positive, holds the maximum protobuf-encoded
+	// size of each batch. A batch always holds at least one event.
+	MaxBatchBytes int
+
+	// Async, if true, makes the consumer process events in the background.
+	// The semaphore is acquired without blocking, and ErrQueueFull is
+	// returned if it is full. Otherwise the payload is converted before
+	// returning, and the resulting batches are passed to Processor in a
+	// background goroutine, which releases the semaphore once done.
+	//
+	// Processing errors are logged for each failed batch rather than
+	// returned, and the remaining batches are still processed. The context
+	// passed to the Consume methods is passed on to Processor after they
+	// have returned; it should not be cancelled when the request completes.
+	//
+	// The whole payload is converted before processing starts, so in async
+	// mode MaxBatchSize and MaxBatchBytes bound the size of each batch,
+	// but not the memory held for a payload.
+	Async bool
 }
 
 // Consumer transforms OpenTelemetry data to the Elastic APM data model,
-// sending each payload as a batch to the configured BatchProcessor.
+// sending each payload as a batch to the configured BatchProcessor, or
+// as multiple batches if MaxBatchSize or MaxBatchBytes is configured.
 type Consumer struct {
 	sem    input.Semaphore
 	config ConsumerConfig
 	stats  consumerStats
+	sums   *sumConverter
 }
 
 // NewConsumer returns a new Consumer with the given configuration.
@@ -56,10 +131,24 @@ func NewConsumer(config ConsumerConfig) *Consumer {
 	} else {
 		config.Logger = config.Logger.Named("otel")
 	}
-	return &Consumer{
+	c := &Consumer{
 		config: config,
 		sem:    config.Semaphore,
 	}
+	if config.SumTemporality != modelpb.AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED {
+		c.sums = newSumConverter(config.SumTemporality, config.SumStateTTL)
+	}
+	return c
+}
+
+func (c *Consumer) semAcquire(ctx context.Context) error {
+	if c.config.Async {
+		if ok := c.sem.TryAcquire(1); !ok {
+			return ErrQueueFull
+		}
+		return nil
+	}
+	return c.sem.Acquire(ctx, 1)
 }
 
 // ConsumerStats holds a snapshot of statistics about data consumption.
@@ -67,18 +156,58 @@ type ConsumerStats struct {
 	// UnsupportedMetricsDropped records the number of unsupported metrics
 	// that have been dropped by the consumer.
 	UnsupportedMetricsDropped int64
+
+	// AcceptedSpans and RejectedSpans record the number of spans
+	// accepted and rejected, as reported by ConsumeTracesWithResult.
+	AcceptedSpans int64
+	RejectedSpans int64
+
+	// AcceptedDataPoints and RejectedDataPoints record the number of
+	// metric data points accepted and rejected, as reported by
+	// ConsumeMetricsWithResult.
+	AcceptedDataPoints int64
+	RejectedDataPoints int64
+
+	// AcceptedLogRecords and RejectedLogRecords record the number of
+	// log records accepted and rejected, as reported by
+	// ConsumeLogsWithResult.
+	AcceptedLogRecords int64
+	RejectedLogRecords int64
 }
 
 // consumerStats holds the current statistics, which must be accessed and
 // modified using atomic operations.
 type consumerStats struct {
 	unsupportedMetricsDropped int64
+	acceptedSpans             int64
+	rejectedSpans             int64
+	acceptedDataPoints        int64
+	rejectedDataPoints        int64
+	acceptedLogRecords        int64
+	rejectedLogRecords        int64
+}
+
+// add adds the given counts of accepted and rejected items to the
+// corresponding statistics.
+func (s *consumerStats) add(accepted, rejected *int64, acceptedN, rejectedN int64) {
+	if acceptedN > 0 {
+		atomic.AddInt64(accepted, acceptedN)
+	}
+	if rejectedN > 0 {
+		atomic.AddInt64(rejected, rejectedN)
+	}
 }
 
 // Stats returns a snapshot of the current statistics about data consumption.
 func (c *Consumer) Stats() ConsumerStats {
 	return ConsumerStats{
 		UnsupportedMetricsDropped: atomic.LoadInt64(&c.stats.unsupportedMetricsDropped),
+		AcceptedSpans:             atomic.LoadInt64(&c.stats.acceptedSpans),
+		RejectedSpans:             atomic.LoadInt64(&c.stats.rejectedSpans),
+		AcceptedDataPoints:        atomic.LoadInt64(&c.stats.acceptedDataPoints),
+		RejectedDataPoints:        atomic.LoadInt64(&c.stats.rejectedDataPoints),
+		AcceptedLogRecords:        atomic.LoadInt64(&c.stats.acceptedLogRecords),
+		RejectedLogRecords:        atomic.LoadInt64(&c.stats.rejectedLogRecords),
 	}
 }
 
@@ -86,6 +86,18 @@ func setExceptionStacktrace(s, language string, out *modelpb.Exception) error {
 	switch language {
 	case "java":
 		return setJavaExceptionStacktrace(s, out)
+	case "python":
+		return setPythonExceptionStacktrace(s, out)
+	case "nodejs", "webjs":
+		return setNodeExceptionStacktrace(s, out)
+	case "dotnet":
+		return setDotnetExceptionStacktrace(s, out)
+	case "ruby":
+		return setRubyExceptionStacktrace(s, out)
+	case "php":
+		return setPHPExceptionStacktrace(s, out)
+	case "go":
+		return setGoExceptionStacktrace(s, out)
 	}
 	return fmt.Errorf("parsing %q stacktraces not implemented", language)
 }
@@ -310,6 +310,206 @@ Caused by: whatever
 	}
 }
 
+func TestEncodeSpanEventsExceptionStacktraces(t *testing.T) {
+	type frame = modelpb.StacktraceFrame
+	for name, test := range map[string]struct {
+		language   string
+		stacktrace string
+		expected   *modelpb.Exception
+	}{
+		"python": {
+			language: "python",
+			stacktrace: `
+Traceback (most recent call last):
+  File "/app/main.py", line 4, in load
+    return config["key"]
+           ~~~~~~^^^^^^^
+KeyError: 'key'
+
+During handling of the above exception, another exception occurred:
+
+Traceback (most recent call last):
+  File "/app/main.py", line 10, in <module>
+    main()
+  File "/app/main.py", line 6, in main
+    raise ValueError("bad config")
+ValueError: bad config`[1:],
+			expected: &modelpb.Exception{
+				Stacktrace: []*frame{
+					{Filename: "/app/main.py", Lineno: newUint32(6), Function: "main", ContextLine: `raise ValueError("bad config")`},
+					{Filename: "/app/main.py", Lineno: newUint32(10), Function: "<module>", ContextLine: "main()"},
+				},
+				Cause: []*modelpb.Exception{{
+					Type:    "KeyError",
+					Message: "'key'",
+					Handled: newBool(true),
+					Stacktrace: []*frame{
+						{Filename: "/app/main.py", Lineno: newUint32(4), Function: "load", ContextLine: `return config["key"]`},
+					},
+				}},
+			},
+		},
+		"nodejs": {
+			language: "nodejs",
+			stacktrace: `
+Error: something bad
+happened
+    at foo (/app/index.js:10:5)
+    at Object.<anonymous> (/app/index.js:20:1)
+    at /app/lib.js:30:7
+    at async Promise.all (index 0)`[1:],
+			expected: &modelpb.Exception{
+				Stacktrace: []*frame{
+					{Function: "foo", Filename: "/app/index.js", Lineno: newUint32(10), Colno: newUint32(5)},
+					{Function: "Object.<anonymous>", Filename: "/app/index.js", Lineno: newUint32(20), Colno: newUint32(1)},
+					{Filename: "/app/lib.js", Lineno: newUint32(30), Colno: newUint32(7)},
+					{Function: "Promise.all", Filename: "index 0"},
+				},
+			},
+		},
+		"dotnet": {
+			language: "dotnet",
+			stacktrace: `
+System.InvalidOperationException: Outer
+ ---> System.ArgumentException: Inner
+   at App.Service.Validate(String s) in /src/Service.cs:line 20
+   at App.Service..ctor() in /src/Service.cs:line 10
+   --- End of inner exception stack trace ---
+   at App.Service..ctor() in /src/Service.cs:line 12
+--- End of stack trace from previous location ---
+   at App.Program.Main(String[] args)`[1:],
+			expected: &modelpb.Exception{
+				Stacktrace: []*frame{
+					{Classname: "App.Service", Function: ".ctor", Filename: "/src/Service.cs", Lineno: newUint32(12)},
+					{Classname: "App.Program", Function: "Main"},
+				},
+				Cause: []*modelpb.Exception{{
+					Type:    "System.ArgumentException",
+					Message: "Inner",
+					Handled: newBool(true),
+					Stacktrace: []*frame{
+						{Classname: "App.Service", Function: "Validate", Filename: "/src/Service.cs", Lineno: newUint32(20)},
+						{Classname: "App.Service", Function: ".ctor", Filename: "/src/Service.cs", Lineno: newUint32(10)},
+					},
+				}},
+			},
+		},
+		"ruby": {
+			language: "ruby",
+			stacktrace: "" +
+				"/app/foo.rb:10:in `bar': outer (RuntimeError)\n" +
+				"\tfrom /app/foo.rb:5:in `foo'\n" +
+				"\tfrom /app/foo.rb:1:in `<main>'\n" +
+				"/app/foo.rb:3:in 'Foo#baz': inner (ArgumentError)\n" +
+				"\tfrom /app/foo.rb:9:in `bar'\n" +
+				"\t ... 2 levels...\n",
+			expected: &modelpb.Exception{
+				Stacktrace: []*frame{
+					{Filename: "/app/foo.rb", Lineno: newUint32(10), Function: "bar"},
+					{Filename: "/app/foo.rb", Lineno: newUint32(5), Function: "foo"},
+					{Filename: "/app/foo.rb", Lineno: newUint32(1), Function: "<main>"},
+				},
+				Cause: []*modelpb.Exception{{
+					Type:    "ArgumentError",
+					Message: "inner",
+					Handled: newBool(true),
+					Stacktrace: []*frame{
+						{Filename: "/app/foo.rb", Lineno: newUint32(3), Function: "Foo#baz"},
+						{Filename: "/app/foo.rb", Lineno: newUint32(9), Function: "bar"},
+					},
+				}},
+			},
+		},
+		"php": {
+			language: "php",
+			stacktrace: `
+Exception: inner in /app/src/Repo.php:3
+Stack trace:
+#0 /app/src/Service.php(10): App\Repo->find('x')
+#1 [internal function]: App\Service::load()
+#2 {main}
+
+Next RuntimeException: outer in /app/src/Service.php:12
+Stack trace:
+#0 /app/index.php(5): App\Service::load()
+#1 {main}`[1:],
+			expected: &modelpb.Exception{
+				Stacktrace: []*frame{
+					{Classname: "App\\Service", Function: "load", Filename: "/app/src/Service.php", Lineno: newUint32(12)},
+					{Filename: "/app/index.php", Lineno: newUint32(5)},
+				},
+				Cause: []*modelpb.Exception{{
+					Type:    "Exception",
+					Message: "inner",
+					Handled: newBool(true),
+					Stacktrace: []*frame{
+						{Classname: "App\\Repo", Function: "find", Filename: "/app/src/Repo.php", Lineno: newUint32(3)},
+						{Classname: "App\\Service", Function: "load", Filename: "/app/src/Service.php", Lineno: newUint32(10)},
+						{},
+					},
+				}},
+			},
+		},
+		"go": {
+			language: "go",
+			stacktrace: `
+panic: something bad [recovered]
+	panic: something bad
+
+goroutine 1 [running]:
+github.com/example/app/internal.(*Handler).Serve(0xc000010000, {0x0, 0x0})
+	/app/internal/handler.go:42 +0x1d
+main.main()
+	/app/main.go:5 +0x25
+
+goroutine 2 [chan receive]:
+main.worker()
+	/app/main.go:15 +0x30`[1:],
+			expected: &modelpb.Exception{
+				Stacktrace: []*frame{
+					{Module: "github.com/example/app/internal", Function: "(*Handler).Serve", Filename: "/app/internal/handler.go", Lineno: newUint32(42)},
+					{Module: "main", Function: "main", Filename: "/app/main.go", Lineno: newUint32(5)},
+				},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			event := ptrace.NewSpanEvent()
+			event.SetName("exception")
+			event.Attributes().PutStr("exception.type", "the_type")
+			event.Attributes().PutStr("exception.message", "the_message")
+			event.Attributes().PutStr("exception.stacktrace", test.stacktrace)
+
+			_, errorEvents := transformTransactionSpanEvents(t, test.language, event)
+			require.Len(t, errorEvents, 1)
+			assert.Empty(t, errorEvents[0].Error.StackTrace)
+
+			test.expected.Type = "the_type"
+			test.expected.Message = "the_message"
+			test.expected.Handled = newBool(true)
+			assert.Empty(t, cmp.Diff(test.expected, errorEvents[0].Error.Exception, protocmp.Transform()))
+		})
+	}
+}
+
+func TestEncodeSpanEventsExceptionsUnparsedStacktrace(t *testing.T) {
+	for _, language := range []string{"python", "nodejs", "dotnet", "ruby", "php", "go"} {
+		t.Run(language, func(t *testing.T) {
+			const stacktrace = "abc\ndef"
+			event := ptrace.NewSpanEvent()
+			event.SetName("exception")
+			event.Attributes().PutStr("exception.type", "ExceptionType")
+			event.Attributes().PutStr("exception.stacktrace", stacktrace)
+
+			_, errorEvents := transformTransactionSpanEvents(t, language, event)
+			require.Len(t, errorEvents, 1)
+			assert.Empty(t, errorEvents[0].Error.Exception.Stacktrace)
+			assert.Empty(t, errorEvents[0].Error.Exception.Cause)
+			assert.Equal(t, stacktrace, errorEvents[0].Error.StackTrace)
+		})
+	}
+}
+
 func TestEncodeSpanEventsNonJavaExceptions(t *testing.T) {
 	timestamp := time.Unix(123, 0).UTC()
 
@@ -43,28 +43,69 @@ import (
 	"go.opentelemetry.io/collector/pdata/plog"
 	semconv "go.opentelemetry.io/collector/semconv/v1.5.0"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/elastic/apm-data/model/modelpb"
 )
 
+const (
+	// Code attributes introduced in semconv v1.26.0.
+	attributeCodeFilePath     = "code.file.path"
+	attributeCodeFunctionName = "code.function.name"
+	attributeCodeLineNumber   = "code.line.number"
+)
+
+// LogMapBodyPolicy controls how log record bodies of type map are recorded.
+type LogMapBodyPolicy int
+
+const (
+	// LogMapBodyLabels records the body, formatted as JSON, as the
+	// event message, and records each of its entries as a label.
+	LogMapBodyLabels LogMapBodyPolicy = iota
+
+	// LogMapBodyStructured records the "message" or "msg" entry of
+	// the body as the event message, and the remaining entries as
+	// the structured log body, retaining their structure.
+	LogMapBodyStructured
+)
+
+// ConsumeLogs consumes OpenTelemetry log data, converting into
+// Elastic APM log events and reporting to the Elastic APM schema.
+//
+// ConsumeLogs implements consumer.Logs; use ConsumeLogsWithResult
+// to learn which log records were rejected.
 func (c *Consumer) ConsumeLogs(ctx context.Context, logs plog.Logs) error {
-	if err := c.sem.Acquire(ctx, 1); err != nil {
-		return err
+	_, err := c.ConsumeLogsWithResult(ctx, logs)
+	return err
+}
+
+// ConsumeLogsWithResult consumes OpenTelemetry log data, converting into
+// Elastic APM events and reporting to the Elastic APM schema. The result
+// records the number of log records accepted and rejected.
+//
+// If processing fails, the error is returned along with a result counting
+// the log records in batches processed before the failure.
+func (c *Consumer) ConsumeLogsWithResult(ctx context.Context, logs plog.Logs) (ConsumeLogsResult, error) {
+	if err := c.semAcquire(ctx); err != nil {
+		return ConsumeLogsResult{}, err
 	}
-	defer c.sem.Release(1)
+	out := c.newBatchSplitter(ctx)
+	defer out.release()
 
 	receiveTimestamp := time.Now()
 	c.config.Logger.Debug("consuming logs", zap.Stringer("logs", logsStringer(logs)))
 	resourceLogs := logs.ResourceLogs()
-	batch := make(modelpb.Batch, 0, resourceLogs.Len())
-	for i := 0; i < resourceLogs.Len(); i++ {
-		c.convertResourceLogs(resourceLogs.At(i), receiveTimestamp, &batch)
+	for i := 0; i < resourceLogs.Len() && out.err == nil; i++ {
+		c.convertResourceLogs(resourceLogs.At(i), receiveTimestamp, out)
 	}
-	return c.config.Processor.ProcessBatch(ctx, &batch)
+	err := out.close()
+	r := ConsumeLogsResult{AcceptedLogRecords: out.accepted}
+	c.stats.add(&c.stats.acceptedLogRecords, &c.stats.rejectedLogRecords, r.AcceptedLogRecords, r.RejectedLogRecords)
+	return r, err
 }
 
-func (c *Consumer) convertResourceLogs(resourceLogs plog.ResourceLogs, receiveTimestamp time.Time, out *modelpb.Batch) {
+func (c *Consumer) convertResourceLogs(resourceLogs plog.ResourceLogs, receiveTimestamp time.Time, out *batchSplitter) {
 	var timeDelta time.Duration
 	resource := resourceLogs.Resource()
 	baseEvent := modelpb.APMEvent{
@@ -79,8 +120,9 @@ func (c *Consumer) convertResourceLogs(resourceLogs plog.ResourceLogs, receiveTi
 		timeDelta = receiveTimestamp.Sub(exportTimestamp)
 	}
 	scopeLogs := resourceLogs.ScopeLogs()
-	for i := 0; i < scopeLogs.Len(); i++ {
+	for i := 0; i < scopeLogs.Len() && out.err == nil; i++ {
 		c.convertInstrumentationLibraryLogs(scopeLogs.At(i), &baseEvent, timeDelta, out)
+		out.boundary()
 	}
 }
 
@@ -88,12 +130,13 @@ func (c *Consumer) convertInstrumentationLibraryLogs(
 	in plog.ScopeLogs,
 	baseEvent *modelpb.APMEvent,
 	timeDelta time.Duration,
-	out *modelpb.Batch,
+	out *batchSplitter,
 ) {
 	otelLogs := in.LogRecords()
 	for i := 0; i < otelLogs.Len(); i++ {
 		event := c.convertLogRecord(otelLogs.At(i), baseEvent, timeDelta)
-		*out = append(*out, event)
+		out.batch = append(out.batch, event)
+		out.mark(1)
 	}
 }
 
@@ -104,15 +147,25 @@ func (c *Consumer) convertLogRecord(
 ) *modelpb.APMEvent {
 	event := baseEvent.CloneVT()
 	initEventLabels(event)
-	event.Timestamp = timestamppb.New(record.Timestamp().AsTime().Add(timeDelta))
+	timestamp := record.Timestamp()
+	if timestamp == 0 {
+		// The time the event occurred is unknown,
+		// so use the time it was observed instead.
+		timestamp = record.ObservedTimestamp()
+	}
+	event.Timestamp = timestamppb.New(timestamp.AsTime().Add(timeDelta))
 	event.Event = populateNil(event.Event)
 	event.Event.Severity = int64(record.SeverityNumber())
 	event.Log = populateNil(event.Log)
 	event.Log.Level = record.SeverityText()
 	if body := record.Body(); body.Type() != pcommon.ValueTypeEmpty {
-		event.Message = body.AsString()
-		if body.Type() == pcommon.ValueTypeMap {
-			setLabels(body.Map(), event)
+		if body.Type() == pcommon.ValueTypeMap && c.config.LogMapBody == LogMapBodyStructured {
+			setStructuredLogBody(body.Map(), event)
+		} else {
+			event.Message = body.AsString()
+			if body.Type() == pcommon.ValueTypeMap {
+				setLabels(body.Map(), event)
+			}
 		}
 	}
 	if traceID := record.TraceID(); !traceID.IsEmpty() {
@@ -148,6 +201,37 @@ func (c *Consumer) convertLogRecord(
 			eventDomain = v.Str()
 		case "session.id":
 			event.Session.Id = v.Str()
+
+		// code.*
+		case semconv.AttributeCodeFilepath, attributeCodeFilePath:
+			event.Log.Origin = populateNil(event.Log.Origin)
+			event.Log.Origin.File = populateNil(event.Log.Origin.File)
+			event.Log.Origin.File.Name = truncate(v.Str())
+		case semconv.AttributeCodeLineNumber, attributeCodeLineNumber:
+			event.Log.Origin = populateNil(event.Log.Origin)
+			event.Log.Origin.File = populateNil(event.Log.Origin.File)
+			event.Log.Origin.File.Line = int32(v.Int())
+		case semconv.AttributeCodeFunction, attributeCodeFunctionName:
+			event.Log.Origin = populateNil(event.Log.Origin)
+			event.Log.Origin.FunctionName = truncate(v.Str())
+
+		// thread.*
+		case semconv.AttributeThreadName:
+			event.Process = populateNil(event.Process)
+			event.Process.Thread = populateNil(event.Process.Thread)
+			event.Process.Thread.Name = truncate(v.Str())
+		case semconv.AttributeThreadID:
+			event.Process = populateNil(event.Process)
+			event.Process.Thread = populateNil(event.Process.Thread)
+			event.Process.Thread.Id = int32(v.Int())
+
+		// miscellaneous
+		case "log.logger":
+			event.Log.Logger = truncate(v.Str())
+		case semconv.AttributeServiceVersion:
+			// Like for spans, service.version may be sent as a log
+			// record attribute, but should be a resource attribute.
+			event.Service.Version = truncate(v.Str())
 		default:
 			setLabel(replaceDots(k), event, ifaceAttributeValue(v))
 		}
@@ -185,6 +269,39 @@ func (c *Consumer) convertLogRecord(
 	return event
 }
 
+// setStructuredLogBody records the "message" or "msg" entry of a map log
+// record body as the event message, and the remaining entries in
+// log.structured.
+func setStructuredLogBody(m pcommon.Map, event *modelpb.APMEvent) {
+	raw := m.AsRaw()
+	var messageKey string
+	for _, k := range []string{"message", "msg"} {
+		if v, ok := raw[k].(string); ok {
+			event.Message = v
+			messageKey = k
+			delete(raw, k)
+			break
+		}
+	}
+	if len(raw) == 0 {
+		return
+	}
+	structured, err := structpb.NewStruct(raw)
+	if err != nil {
+		// structpb cannot represent strings with invalid UTF-8, so fall
+		// back to recording the remaining entries as labels. Bytes values
+		// do not fail: they are recorded as base64-encoded strings.
+		m.Range(func(k string, v pcommon.Value) bool {
+			if k != messageKey {
+				setLabel(replaceDots(k), event, ifaceAttributeValue(v))
+			}
+			return true
+		})
+		return
+	}
+	event.Log.Structured = structured
+}
+
 func setLabels(m pcommon.Map, event *modelpb.APMEvent) {
 	m.Range(func(k string, v pcommon.Value) bool {
 		setLabel(replaceDots(k), event, ifaceAttributeValue(v))
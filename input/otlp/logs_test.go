@@ -41,6 +41,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
 	semconv "go.opentelemetry.io/collector/semconv/v1.5.0"
@@ -160,6 +161,33 @@ func TestConsumeLogsSemaphore(t *testing.T) {
 	assert.NoError(t, consumer.ConsumeLogs(context.Background(), logs))
 }
 
+func TestConsumeLogsAsync(t *testing.T) {
+	logs := plog.NewLogs()
+	newLogRecord("foo").CopyTo(logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty())
+
+	batchCh := make(chan *modelpb.Batch, 1)
+	sem := &fakeSemaphore{available: 1, released: make(chan struct{}, 1)}
+	consumer := otlp.NewConsumer(otlp.ConsumerConfig{
+		Processor: modelpb.ProcessBatchFunc(func(ctx context.Context, batch *modelpb.Batch) error {
+			batchCh <- batch
+			return nil
+		}),
+		Semaphore: sem,
+		Async:     true,
+	})
+
+	result, err := consumer.ConsumeLogsWithResult(context.Background(), logs)
+	require.NoError(t, err)
+	assert.Equal(t, otlp.ConsumeLogsResult{AcceptedLogRecords: 1}, result)
+	batch := <-batchCh
+	<-sem.released
+	require.Len(t, *batch, 1)
+	assert.Equal(t, "foo", (*batch)[0].Message)
+
+	sem.available = 0
+	assert.Equal(t, otlp.ErrQueueFull, consumer.ConsumeLogs(context.Background(), logs))
+}
+
 func TestConsumerConsumeLogsException(t *testing.T) {
 	logs := plog.NewLogs()
 	resourceLogs := logs.ResourceLogs().AppendEmpty()
@@ -415,6 +443,181 @@ func TestConsumerConsumeLogsLabels(t *testing.T) {
 	assert.Equal(t, modelpb.NumericLabels{"key4": {Value: 4}}, modelpb.NumericLabels(processed[2].NumericLabels))
 }
 
+func TestConsumerConsumeLogsECSFields(t *testing.T) {
+	logs := plog.NewLogs()
+	resourceLogs := logs.ResourceLogs().AppendEmpty()
+	scopeLogs := resourceLogs.ScopeLogs().AppendEmpty()
+
+	record1 := newLogRecord("whatever")
+	record1.Attributes().PutStr("code.filepath", "/app/main.go")
+	record1.Attributes().PutInt("code.lineno", 42)
+	record1.Attributes().PutStr("code.function", "main.main")
+	record1.Attributes().PutStr("thread.name", "main")
+	record1.Attributes().PutInt("thread.id", 1)
+	record1.Attributes().PutStr("log.logger", "app.logger")
+	record1.Attributes().PutStr("service.version", "1.2.3")
+	record1.CopyTo(scopeLogs.LogRecords().AppendEmpty())
+
+	record2 := newLogRecord("andever")
+	record2.Attributes().PutStr("code.file.path", "/app/main.py")
+	record2.Attributes().PutInt("code.line.number", 7)
+	record2.Attributes().PutStr("code.function.name", "handler")
+	observed := time.Unix(123, 0).UTC()
+	record2.SetTimestamp(0)
+	record2.SetObservedTimestamp(pcommon.NewTimestampFromTime(observed))
+	record2.CopyTo(scopeLogs.LogRecords().AppendEmpty())
+
+	processed := consumeLogs(t, otlp.ConsumerConfig{}, logs)
+	require.Len(t, processed, 2)
+	assert.Empty(t, cmp.Diff(&modelpb.Log{
+		Level:  "Info",
+		Logger: "app.logger",
+		Origin: &modelpb.LogOrigin{
+			FunctionName: "main.main",
+			File:         &modelpb.LogOriginFile{Name: "/app/main.go", Line: 42},
+		},
+	}, processed[0].Log, protocmp.Transform()))
+	assert.Empty(t, cmp.Diff(&modelpb.Process{
+		Thread: &modelpb.ProcessThread{Name: "main", Id: 1},
+	}, processed[0].Process, protocmp.Transform()))
+	assert.Equal(t, "1.2.3", processed[0].Service.Version)
+	assert.Empty(t, processed[0].Labels)
+	assert.Empty(t, processed[0].NumericLabels)
+
+	assert.Empty(t, cmp.Diff(&modelpb.Log{
+		Level: "Info",
+		Origin: &modelpb.LogOrigin{
+			FunctionName: "handler",
+			File:         &modelpb.LogOriginFile{Name: "/app/main.py", Line: 7},
+		},
+	}, processed[1].Log, protocmp.Transform()))
+	assert.Equal(t, observed, processed[1].Timestamp.AsTime())
+}
+
+func TestConsumerConsumeLogsMapBody(t *testing.T) {
+	newLogs := func() plog.Logs {
+		logs := plog.NewLogs()
+		scopeLogs := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty()
+		record := newLogRecord("")
+		record.Body().SetEmptyMap().FromRaw(map[string]interface{}{
+			"msg":   "request handled",
+			"level": "info",
+			"http": map[string]interface{}{
+				"status": 200,
+			},
+		})
+		record.CopyTo(scopeLogs.LogRecords().AppendEmpty())
+		return logs
+	}
+
+	t.Run("labels", func(t *testing.T) {
+		processed := consumeLogs(t, otlp.ConsumerConfig{}, newLogs())
+		require.Len(t, processed, 1)
+		assert.Equal(t, `{"http":{"status":200},"level":"info","msg":"request handled"}`, processed[0].Message)
+		assert.Nil(t, processed[0].Log.Structured)
+		assert.Equal(t, modelpb.Labels{
+			"msg":   {Value: "request handled"},
+			"level": {Value: "info"},
+		}, modelpb.Labels(processed[0].Labels))
+	})
+
+	t.Run("structured", func(t *testing.T) {
+		processed := consumeLogs(t, otlp.ConsumerConfig{
+			LogMapBody: otlp.LogMapBodyStructured,
+		}, newLogs())
+		require.Len(t, processed, 1)
+		assert.Equal(t, "request handled", processed[0].Message)
+		assert.Empty(t, processed[0].Labels)
+		assert.Empty(t, processed[0].NumericLabels)
+		assert.Equal(t, map[string]interface{}{
+			"level": "info",
+			"http": map[string]interface{}{
+				"status": float64(200),
+			},
+		}, processed[0].Log.Structured.AsMap())
+	})
+
+	t.Run("structured_bytes", func(t *testing.T) {
+		logs := plog.NewLogs()
+		record := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+		body := record.Body().SetEmptyMap()
+		body.PutStr("message", "request handled")
+		body.PutEmptyBytes("payload").FromRaw([]byte("abc"))
+
+		processed := consumeLogs(t, otlp.ConsumerConfig{
+			LogMapBody: otlp.LogMapBodyStructured,
+		}, logs)
+		require.Len(t, processed, 1)
+		assert.Equal(t, "request handled", processed[0].Message)
+		assert.Empty(t, processed[0].Labels)
+		assert.Equal(t, map[string]interface{}{
+			"payload": "YWJj",
+		}, processed[0].Log.Structured.AsMap())
+	})
+
+	t.Run("structured_invalid_utf8", func(t *testing.T) {
+		logs := plog.NewLogs()
+		record := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+		body := record.Body().SetEmptyMap()
+		body.PutStr("message", "request handled")
+		body.PutStr("level", "info")
+		body.PutStr("invalid", "\xff")
+
+		// Entries are recorded as labels, except for the message.
+		processed := consumeLogs(t, otlp.ConsumerConfig{
+			LogMapBody: otlp.LogMapBodyStructured,
+		}, logs)
+		require.Len(t, processed, 1)
+		assert.Equal(t, "request handled", processed[0].Message)
+		assert.Nil(t, processed[0].Log.Structured)
+		assert.Equal(t, modelpb.Labels{
+			"level":   {Value: "info"},
+			"invalid": {Value: "\xff"},
+		}, modelpb.Labels(processed[0].Labels))
+	})
+}
+
+func TestConsumerConsumeLogsWithResult(t *testing.T) {
+	logs := plog.NewLogs()
+	scopeLogs := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty()
+	newLogRecord("one").CopyTo(scopeLogs.LogRecords().AppendEmpty())
+	newLogRecord("two").CopyTo(scopeLogs.LogRecords().AppendEmpty())
+
+	var processed modelpb.Batch
+	consumer := otlp.NewConsumer(otlp.ConsumerConfig{
+		Processor: modelpb.ProcessBatchFunc(func(_ context.Context, batch *modelpb.Batch) error {
+			processed = *batch
+			return nil
+		}),
+		Semaphore: semaphore.NewWeighted(100),
+	})
+	result, err := consumer.ConsumeLogsWithResult(context.Background(), logs)
+	require.NoError(t, err)
+	assert.Len(t, processed, 2)
+	assert.Equal(t, otlp.ConsumeLogsResult{AcceptedLogRecords: 2}, result)
+
+	// Nothing was rejected, so the response must not report partial success.
+	partialSuccess := result.ExportResponse().PartialSuccess()
+	assert.Zero(t, partialSuccess.RejectedLogRecords())
+	assert.Empty(t, partialSuccess.ErrorMessage())
+	assert.Equal(t, int64(2), consumer.Stats().AcceptedLogRecords)
+}
+
+func consumeLogs(t *testing.T, config otlp.ConsumerConfig, logs plog.Logs) modelpb.Batch {
+	var processed modelpb.Batch
+	config.Processor = modelpb.ProcessBatchFunc(func(_ context.Context, batch *modelpb.Batch) error {
+		if processed != nil {
+			panic("already processes batch")
+		}
+		processed = *batch
+		return nil
+	})
+	config.Semaphore = semaphore.NewWeighted(100)
+	consumer := otlp.NewConsumer(config)
+	require.NoError(t, consumer.ConsumeLogs(context.Background(), logs))
+	return processed
+}
+
 func newLogRecord(body interface{}) plog.LogRecord {
 	otelLogRecord := plog.NewLogRecord()
 	otelLogRecord.SetTraceID(pcommon.TraceID{1})
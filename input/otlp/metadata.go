@@ -40,6 +40,8 @@ var (
 
 func translateResourceMetadata(resource pcommon.Resource, out *modelpb.APMEvent) {
 	var exporterVersion string
+	var faasInstance string
+	var cloudPlatform, cloudResourceID string
 	resource.Attributes().Range(func(k string, v pcommon.Value) bool {
 		switch k {
 		// service.*
@@ -86,7 +88,25 @@ func translateResourceMetadata(resource pcommon.Resource, out *modelpb.APMEvent)
 			out.Cloud.AvailabilityZone = truncate(v.Str())
 		case semconv.AttributeCloudPlatform:
 			out.Cloud = populateNil(out.Cloud)
-			out.Cloud.ServiceName = truncate(v.Str())
+			cloudPlatform = truncate(v.Str())
+			out.Cloud.ServiceName = cloudServiceName(cloudPlatform)
+		case "cloud.resource_id":
+			// Only functions have an Elastic equivalent; this is
+			// handled below, once all attributes have been seen.
+			cloudResourceID = truncate(v.Str())
+
+		// faas.*
+		case semconv.AttributeFaaSID:
+			out.Faas = populateNil(out.Faas)
+			out.Faas.Id = truncate(v.Str())
+		case semconv.AttributeFaaSName:
+			out.Faas = populateNil(out.Faas)
+			out.Faas.Name = truncate(v.Str())
+		case semconv.AttributeFaaSVersion:
+			out.Faas = populateNil(out.Faas)
+			out.Faas.Version = truncate(v.Str())
+		case semconv.AttributeFaaSInstance:
+			faasInstance = truncate(v.Str())
 
 		// container.*
 		case semconv.AttributeContainerName:
@@ -255,6 +275,45 @@ func translateResourceMetadata(resource pcommon.Resource, out *modelpb.APMEvent)
 		}
 	}
 
+	if cloudResourceID != "" {
+		if out.Faas != nil || isFaaSPlatform(cloudPlatform) {
+			// faas.id was replaced by cloud.resource_id, which
+			// takes precedence if both are specified.
+			out.Faas = populateNil(out.Faas)
+			out.Faas.Id = cloudResourceID
+		} else {
+			if out.Labels == nil {
+				out.Labels = make(modelpb.Labels)
+			}
+			setLabel(replaceDots("cloud.resource_id"), out, cloudResourceID)
+		}
+	}
+
+	if out.Faas != nil {
+		// Fill in service fields from the function, matching
+		// the defaults of the Elastic APM agents for functions.
+		out.Service = populateNil(out.Service)
+		if name := out.Service.Name; out.Faas.Name != "" && (name == "" || strings.HasPrefix(name, "unknown_service")) {
+			out.Service.Name = cleanServiceName(out.Faas.Name)
+		}
+		if out.Service.Version == "" {
+			out.Service.Version = out.Faas.Version
+		}
+		if faasInstance != "" && out.Service.GetNode().GetName() == "" {
+			out.Service.Node = populateNil(out.Service.Node)
+			out.Service.Node.Name = faasInstance
+			faasInstance = ""
+		}
+	}
+	if faasInstance != "" {
+		// faas.instance is only used as the service node name for
+		// functions, and is otherwise recorded as a label.
+		if out.Labels == nil {
+			out.Labels = make(modelpb.Labels)
+		}
+		setLabel(replaceDots(semconv.AttributeFaaSInstance), out, faasInstance)
+	}
+
 	if out.GetService().GetName() == "" {
 		out.Service = populateNil(out.Service)
 		// service.name is a required field.
@@ -289,6 +348,34 @@ func translateResourceMetadata(resource pcommon.Resource, out *modelpb.APMEvent)
 	}
 }
 
+// cloudServiceName returns the Elastic APM cloud.service.name for the
+// given OpenTelemetry cloud.platform, for those platforms whose names
+// differ. Other platform names are returned unchanged.
+func cloudServiceName(platform string) string {
+	switch platform {
+	case semconv.AttributeCloudPlatformAWSLambda:
+		return "lambda"
+	case semconv.AttributeCloudPlatformAzureFunctions:
+		return "functions"
+	}
+	return platform
+}
+
+// isFaaSPlatform reports whether the given OpenTelemetry cloud.platform
+// is a function as a service platform.
+func isFaaSPlatform(platform string) bool {
+	switch platform {
+	case semconv.AttributeCloudPlatformAWSLambda,
+		semconv.AttributeCloudPlatformAzureFunctions,
+		semconv.AttributeCloudPlatformGCPCloudFunctions,
+		"alibaba_cloud_fc",
+		"ibm_cloud_openwhisk",
+		"tencent_cloud_scf":
+		return true
+	}
+	return false
+}
+
 func cleanServiceName(name string) string {
 	return serviceNameInvalidRegexp.ReplaceAllString(truncate(name), "_")
 }
@@ -324,15 +411,19 @@ func initEventLabels(e *modelpb.APMEvent) {
 }
 
 func setLabel(key string, event *modelpb.APMEvent, v interface{}) {
+	setLabelValue(key, event.Labels, event.NumericLabels, v)
+}
+
+func setLabelValue(key string, labels modelpb.Labels, numericLabels modelpb.NumericLabels, v interface{}) {
 	switch v := v.(type) {
 	case string:
-		modelpb.Labels(event.Labels).Set(key, v)
+		labels.Set(key, v)
 	case bool:
-		modelpb.Labels(event.Labels).Set(key, strconv.FormatBool(v))
+		labels.Set(key, strconv.FormatBool(v))
 	case float64:
-		modelpb.NumericLabels(event.NumericLabels).Set(key, v)
+		numericLabels.Set(key, v)
 	case int64:
-		modelpb.NumericLabels(event.NumericLabels).Set(key, float64(v))
+		numericLabels.Set(key, float64(v))
 	case []interface{}:
 		if len(v) == 0 {
 			return
@@ -343,13 +434,13 @@ func setLabel(key string, event *modelpb.APMEvent, v interface{}) {
 			for i := range v {
 				value[i] = v[i].(string)
 			}
-			modelpb.Labels(event.Labels).SetSlice(key, value)
+			labels.SetSlice(key, value)
 		case float64:
 			value := make([]float64, len(v))
 			for i := range v {
 				value[i] = v[i].(float64)
 			}
-			modelpb.NumericLabels(event.NumericLabels).SetSlice(key, value)
+			numericLabels.SetSlice(key, value)
 		}
 	}
 }
@@ -112,6 +112,77 @@ func TestResourceConventions(t *testing.T) {
 				},
 			},
 		},
+		"faas": {
+			attrs: map[string]interface{}{
+				"service.name":      "unknown_service:java",
+				"cloud.platform":    "aws_lambda",
+				"faas.id":           "faas_id",
+				"cloud.resource_id": "cloud_resource_id",
+				"faas.name":         "faas_name",
+				"faas.version":      "faas_version",
+				"faas.instance":     "faas_instance",
+			},
+			expected: &modelpb.APMEvent{
+				Agent: &defaultAgent,
+				Service: &modelpb.Service{
+					Name:     "faas_name",
+					Version:  "faas_version",
+					Node:     &modelpb.ServiceNode{Name: "faas_instance"},
+					Language: &modelpb.Language{Name: "unknown"},
+				},
+				Cloud: &modelpb.Cloud{ServiceName: "lambda"},
+				Faas: &modelpb.Faas{
+					Id:      "cloud_resource_id",
+					Name:    "faas_name",
+					Version: "faas_version",
+				},
+			},
+		},
+		"faas_cloud_resource_id": {
+			attrs: map[string]interface{}{
+				"service.name":      "service_name",
+				"cloud.platform":    "gcp_cloud_functions",
+				"cloud.resource_id": "cloud_resource_id",
+			},
+			expected: &modelpb.APMEvent{
+				Agent: &defaultAgent,
+				Service: &modelpb.Service{
+					Name:     "service_name",
+					Language: &modelpb.Language{Name: "unknown"},
+				},
+				Cloud: &modelpb.Cloud{ServiceName: "gcp_cloud_functions"},
+				Faas:  &modelpb.Faas{Id: "cloud_resource_id"},
+			},
+		},
+		"cloud_resource_id": {
+			// cloud.resource_id is only recorded as faas.id for functions.
+			attrs: map[string]interface{}{
+				"cloud.platform":    "aws_ec2",
+				"cloud.resource_id": "cloud_resource_id",
+			},
+			expected: &modelpb.APMEvent{
+				Agent:   &defaultAgent,
+				Service: &defaultService,
+				Cloud:   &modelpb.Cloud{ServiceName: "aws_ec2"},
+				Labels: modelpb.Labels{
+					"cloud_resource_id": {Value: "cloud_resource_id", Global: true},
+				},
+			},
+		},
+		"faas_instance": {
+			// faas.instance is only recorded as the service node name
+			// for functions.
+			attrs: map[string]interface{}{
+				"faas.instance": "faas_instance",
+			},
+			expected: &modelpb.APMEvent{
+				Agent:   &defaultAgent,
+				Service: &defaultService,
+				Labels: modelpb.Labels{
+					"faas_instance": {Value: "faas_instance", Global: true},
+				},
+			},
+		},
 		"container": {
 			attrs: map[string]interface{}{
 				"container.name":       "container_name",
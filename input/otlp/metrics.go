@@ -51,28 +51,54 @@ import (
 
 // ConsumeMetrics consumes OpenTelemetry metrics data, converting into
 // the Elastic APM metrics model and sending to the reporter.
+//
+// ConsumeMetrics implements consumer.Metrics; use ConsumeMetricsWithResult
+// to learn which data points were rejected.
 func (c *Consumer) ConsumeMetrics(ctx context.Context, metrics pmetric.Metrics) error {
-	if err := c.sem.Acquire(ctx, 1); err != nil {
-		return err
+	_, err := c.ConsumeMetricsWithResult(ctx, metrics)
+	return err
+}
+
+// ConsumeMetricsWithResult consumes OpenTelemetry metrics data, converting
+// into the Elastic APM metrics model and sending to the reporter. The result
+// records the number of data points accepted and rejected.
+//
+// If processing fails, the error is returned along with a result counting
+// the data points in batches processed before the failure.
+func (c *Consumer) ConsumeMetricsWithResult(ctx context.Context, metrics pmetric.Metrics) (ConsumeMetricsResult, error) {
+	if err := c.semAcquire(ctx); err != nil {
+		return ConsumeMetricsResult{}, err
 	}
-	defer c.sem.Release(1)
+	out := c.newBatchSplitter(ctx)
+	defer out.release()
 
 	receiveTimestamp := time.Now()
 	c.config.Logger.Debug("consuming metrics", zap.Stringer("metrics", metricsStringer(metrics)))
-	batch := c.convertMetrics(metrics, receiveTimestamp)
-	return c.config.Processor.ProcessBatch(ctx, batch)
+	var result consumeResult
+	c.convertMetrics(metrics, receiveTimestamp, &result, out)
+	err := out.close()
+	r := ConsumeMetricsResult{
+		AcceptedDataPoints: out.accepted,
+		RejectedDataPoints: result.rejectedCount(),
+		ErrorMessage:       result.errorMessage(),
+	}
+	c.stats.add(&c.stats.acceptedDataPoints, &c.stats.rejectedDataPoints, r.AcceptedDataPoints, r.RejectedDataPoints)
+	return r, err
 }
 
-func (c *Consumer) convertMetrics(metrics pmetric.Metrics, receiveTimestamp time.Time) *modelpb.Batch {
-	batch := modelpb.Batch{}
+func (c *Consumer) convertMetrics(metrics pmetric.Metrics, receiveTimestamp time.Time, result *consumeResult, out *batchSplitter) {
 	resourceMetrics := metrics.ResourceMetrics()
-	for i := 0; i < resourceMetrics.Len(); i++ {
-		c.convertResourceMetrics(resourceMetrics.At(i), receiveTimestamp, &batch)
+	for i := 0; i < resourceMetrics.Len() && out.err == nil; i++ {
+		c.convertResourceMetrics(resourceMetrics.At(i), receiveTimestamp, result, out)
 	}
-	return &batch
 }
 
-func (c *Consumer) convertResourceMetrics(resourceMetrics pmetric.ResourceMetrics, receiveTimestamp time.Time, out *modelpb.Batch) {
+func (c *Consumer) convertResourceMetrics(
+	resourceMetrics pmetric.ResourceMetrics,
+	receiveTimestamp time.Time,
+	result *consumeResult,
+	out *batchSplitter,
+) {
 	baseEvent := modelpb.APMEvent{
 		Event: &modelpb.Event{
 			Received: timestamppb.New(receiveTimestamp),
@@ -85,23 +111,39 @@ func (c *Consumer) convertResourceMetrics(resourceMetrics pmetric.ResourceMetric
 	if exportTimestamp, ok := exportTimestamp(resource); ok {
 		timeDelta = receiveTimestamp.Sub(exportTimestamp)
 	}
+	var resourceKey string
+	if c.sums != nil {
+		resourceKey = attributesSignature(resource.Attributes())
+	}
 	scopeMetrics := resourceMetrics.ScopeMetrics()
-	for i := 0; i < scopeMetrics.Len(); i++ {
-		c.convertScopeMetrics(scopeMetrics.At(i), &baseEvent, timeDelta, out)
+	for i := 0; i < scopeMetrics.Len() && out.err == nil; i++ {
+		// Data points are aggregated into metricsets, so they are
+		// only counted as accepted once the whole scope is processed.
+		accepted := result.accepted
+		c.convertScopeMetrics(scopeMetrics.At(i), &baseEvent, resourceKey, timeDelta, result, &out.batch)
+		out.mark(result.accepted - accepted)
+		out.boundary()
 	}
 }
 
 func (c *Consumer) convertScopeMetrics(
 	in pmetric.ScopeMetrics,
 	baseEvent *modelpb.APMEvent,
+	resourceKey string,
 	timeDelta time.Duration,
+	result *consumeResult,
 	out *modelpb.Batch,
 ) {
 	ms := make(metricsets)
+	series := sumKey{
+		resource:     resourceKey,
+		scope:        in.Scope().Name(),
+		scopeVersion: in.Scope().Version(),
+	}
 	otelMetrics := in.Metrics()
 	var unsupported int64
 	for i := 0; i < otelMetrics.Len(); i++ {
-		if !c.addMetric(otelMetrics.At(i), ms) {
+		if !c.addMetric(otelMetrics.At(i), series, ms, result) {
 			unsupported++
 		}
 	}
@@ -134,8 +176,11 @@ func (c *Consumer) convertScopeMetrics(
 	}
 }
 
-func (c *Consumer) addMetric(metric pmetric.Metric, ms metricsets) bool {
-	// TODO(axw) support units
+// addMetric converts metric, adding its samples to ms and recording
+// accepted and rejected data points in result. The resource and scope
+// of series identify the metric's origin for sum conversion.
+func (c *Consumer) addMetric(metric pmetric.Metric, series sumKey, ms metricsets, result *consumeResult) bool {
+	unit := translateUnit(metric.Unit(), c.config.NormalizeMetricUnits)
 	anyDropped := false
 	switch metric.Type() {
 	case pmetric.MetricTypeGauge:
@@ -144,22 +189,56 @@ func (c *Consumer) addMetric(metric pmetric.Metric, ms metricsets) bool {
 			dp := dps.At(i)
 			if sample, ok := numberSample(dp, modelpb.MetricType_METRIC_TYPE_GAUGE); ok {
 				sample.Name = metric.Name()
+				unit.apply(&sample)
 				ms.upsert(dp.Timestamp().AsTime(), dp.Attributes(), &sample)
+				result.accepted++
 			} else {
 				anyDropped = true
+				result.reject(rejectedInvalidNumberValue)
 			}
 		}
 		return !anyDropped
 	case pmetric.MetricTypeSum:
-		dps := metric.Sum().DataPoints()
+		sum := metric.Sum()
+		// Non-monotonic sums, such as up-down counters, are recorded as
+		// gauges: they may increase or decrease, and cannot be used to
+		// calculate rates.
+		metricType := modelpb.MetricType_METRIC_TYPE_GAUGE
+		if sum.IsMonotonic() {
+			metricType = modelpb.MetricType_METRIC_TYPE_COUNTER
+		}
+		temporality := aggregationTemporality(sum.AggregationTemporality())
+		dps := sum.DataPoints()
 		for i := 0; i < dps.Len(); i++ {
 			dp := dps.At(i)
-			if sample, ok := numberSample(dp, modelpb.MetricType_METRIC_TYPE_COUNTER); ok {
-				sample.Name = metric.Name()
-				ms.upsert(dp.Timestamp().AsTime(), dp.Attributes(), &sample)
-			} else {
+			sample, ok := numberSample(dp, metricType)
+			if !ok {
 				anyDropped = true
+				result.reject(rejectedInvalidNumberValue)
+				continue
 			}
+			sample.Name = metric.Name()
+			unit.apply(&sample)
+			sample.Temporality = temporality
+			if c.sums != nil {
+				series.name = metric.Name()
+				series.attributes = attributesSignature(dp.Attributes())
+				switch c.sums.convert(series, dp, sum.IsMonotonic(), &sample) {
+				case sumSeeded:
+					// The first data point of a cumulative series has no
+					// previous value from which to calculate a delta. It
+					// is expected for every new series, e.g. after a
+					// restart, so it is consumed without being recorded
+					// or reported as rejected.
+					result.accepted++
+					continue
+				case sumOutOfOrder:
+					result.reject(rejectedSumOutOfOrder)
+					continue
+				}
+			}
+			ms.upsert(dp.Timestamp().AsTime(), dp.Attributes(), &sample)
+			result.accepted++
 		}
 		return !anyDropped
 	case pmetric.MetricTypeHistogram:
@@ -168,9 +247,28 @@ func (c *Consumer) addMetric(metric pmetric.Metric, ms metricsets) bool {
 			dp := dps.At(i)
 			if sample, ok := histogramSample(dp.BucketCounts(), dp.ExplicitBounds()); ok {
 				sample.Name = metric.Name()
+				sample.Exemplars = exemplars(dp.Exemplars())
+				unit.apply(sample)
+				ms.upsert(dp.Timestamp().AsTime(), dp.Attributes(), sample)
+				result.accepted++
+			} else {
+				anyDropped = true
+				result.reject(rejectedInvalidHistogram)
+			}
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dps := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			if sample, ok := exponentialHistogramSample(dp); ok {
+				sample.Name = metric.Name()
+				sample.Exemplars = exemplars(dp.Exemplars())
+				unit.apply(sample)
 				ms.upsert(dp.Timestamp().AsTime(), dp.Attributes(), sample)
+				result.accepted++
 			} else {
 				anyDropped = true
+				result.reject(rejectedInvalidExponentialHistogram)
 			}
 		}
 	case pmetric.MetricTypeSummary:
@@ -179,11 +277,14 @@ func (c *Consumer) addMetric(metric pmetric.Metric, ms metricsets) bool {
 			dp := dps.At(i)
 			sample := summarySample(dp)
 			sample.Name = metric.Name()
+			unit.apply(sample)
 			ms.upsert(dp.Timestamp().AsTime(), dp.Attributes(), sample)
+			result.accepted++
 		}
 	default:
 		// Unsupported metric: report that it has been dropped.
 		anyDropped = true
+		result.reject(rejectedUnsupportedMetricType)
 	}
 	return !anyDropped
 }
@@ -202,11 +303,55 @@ func numberSample(dp pmetric.NumberDataPoint, metricType modelpb.MetricType) (mo
 		return modelpb.MetricsetSample{}, false
 	}
 	return modelpb.MetricsetSample{
-		Type:  metricType,
-		Value: value,
+		Type:      metricType,
+		Value:     value,
+		Exemplars: exemplars(dp.Exemplars()),
 	}, true
 }
 
+// exemplars converts OTLP exemplars, which link a sample to traces in
+// which it was measured. Exemplars with invalid values are dropped.
+func exemplars(in pmetric.ExemplarSlice) []*modelpb.Exemplar {
+	var out []*modelpb.Exemplar
+	for i := 0; i < in.Len(); i++ {
+		e := in.At(i)
+		var value float64
+		switch e.ValueType() {
+		case pmetric.ExemplarValueTypeInt:
+			value = float64(e.IntValue())
+		case pmetric.ExemplarValueTypeDouble:
+			value = e.DoubleValue()
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				continue
+			}
+		default:
+			continue
+		}
+		exemplar := &modelpb.Exemplar{
+			Timestamp: timestamppb.New(e.Timestamp().AsTime()),
+			Value:     value,
+			TraceId:   hexTraceID(e.TraceID()),
+			SpanId:    hexSpanID(e.SpanID()),
+		}
+		if attributes := e.FilteredAttributes(); attributes.Len() > 0 {
+			exemplar.Labels = make(modelpb.Labels)
+			exemplar.NumericLabels = make(modelpb.NumericLabels)
+			attributes.Range(func(k string, v pcommon.Value) bool {
+				setLabelValue(k, exemplar.Labels, exemplar.NumericLabels, ifaceAttributeValue(v))
+				return true
+			})
+			if len(exemplar.Labels) == 0 {
+				exemplar.Labels = nil
+			}
+			if len(exemplar.NumericLabels) == 0 {
+				exemplar.NumericLabels = nil
+			}
+		}
+		out = append(out, exemplar)
+	}
+	return out
+}
+
 func summarySample(dp pmetric.SummaryDataPoint) *modelpb.MetricsetSample {
 	return &modelpb.MetricsetSample{
 		Type: modelpb.MetricType_METRIC_TYPE_SUMMARY,
@@ -282,11 +427,92 @@ func histogramSample(bucketCounts pcommon.UInt64Slice, explicitBounds pcommon.Fl
 	}, true
 }
 
+// exponentialHistogramSample converts an exponential histogram data point
+// to the values/counts histogram representation.
+//
+// (From opentelemetry-proto/opentelemetry/proto/metrics/v1/metrics.proto)
+//
+// The histogram bucket identified by `index`, a signed integer, represents
+// values in the population that are greater than base**index and less than
+// or equal to base**(index+1), where base = 2**(2**(-scale)).
+//
+// Each non-empty bucket is represented by the midpoint of its boundaries,
+// as for explicit bucket histograms, with negative buckets mirrored and
+// zero-count values represented by 0. For a value v within a bucket, the
+// error introduced by this is at most v*(base-1)/2: for example, 50% at
+// scale 0, 4.5% at scale 3, and 0.14% at scale 8.
+func exponentialHistogramSample(dp pmetric.ExponentialHistogramDataPoint) (*modelpb.MetricsetSample, bool) {
+	scale := dp.Scale()
+	if scale < -10 || scale > 20 {
+		return &modelpb.MetricsetSample{}, false
+	}
+	negative, positive := dp.Negative(), dp.Positive()
+	negativeCounts, positiveCounts := negative.BucketCounts(), positive.BucketCounts()
+	n := negativeCounts.Len() + positiveCounts.Len() + 1
+	values := make([]float64, 0, n)
+	counts := make([]int64, 0, n)
+
+	// Negative buckets are added in decreasing order of index,
+	// so that values are in increasing order.
+	for i := negativeCounts.Len() - 1; i >= 0; i-- {
+		count := negativeCounts.At(i)
+		if count == 0 {
+			continue
+		}
+		value := exponentialBucketMidpoint(scale, negative.Offset()+int32(i))
+		if math.IsInf(value, 0) {
+			return &modelpb.MetricsetSample{}, false
+		}
+		counts = append(counts, int64(count))
+		values = append(values, -value)
+	}
+	if count := dp.ZeroCount(); count != 0 {
+		counts = append(counts, int64(count))
+		values = append(values, 0)
+	}
+	for i := 0; i < positiveCounts.Len(); i++ {
+		count := positiveCounts.At(i)
+		if count == 0 {
+			continue
+		}
+		value := exponentialBucketMidpoint(scale, positive.Offset()+int32(i))
+		if math.IsInf(value, 0) {
+			return &modelpb.MetricsetSample{}, false
+		}
+		counts = append(counts, int64(count))
+		values = append(values, value)
+	}
+	return &modelpb.MetricsetSample{
+		Type: modelpb.MetricType_METRIC_TYPE_HISTOGRAM,
+		Histogram: &modelpb.Histogram{
+			Counts: counts,
+			Values: values,
+		},
+	}, true
+}
+
+// exponentialBucketMidpoint returns the midpoint between the boundaries
+// of the positive exponential histogram bucket with the given index.
+func exponentialBucketMidpoint(scale, index int32) float64 {
+	exp := math.Exp2(-float64(scale))
+	lower := math.Exp2(float64(index) * exp)
+	upper := math.Exp2(float64(index+1) * exp)
+	if math.IsInf(upper, 1) {
+		return lower
+	}
+	return lower + (upper-lower)/2
+}
+
 type metricsets map[metricsetKey]metricset
 
 type metricsetKey struct {
 	timestamp time.Time
 	signature string // combination of all attributes
+
+	// elastic is true for metricsets holding Elastic APM metrics copied
+	// from OpenTelemetry metrics. These are kept apart from the original
+	// metrics, so that they are routed to the internal metrics data stream.
+	elastic bool
 }
 
 type metricset struct {
@@ -300,17 +526,44 @@ type metricset struct {
 func (ms metricsets) upsert(timestamp time.Time, attributes pcommon.Map, sample *modelpb.MetricsetSample) {
 	// We always record metrics as they are given. We also copy some
 	// well-known OpenTelemetry metrics to their Elastic APM equivalents.
-	ms.upsertOne(timestamp, attributes, sample)
+	ms.get(timestamp, attributes, false).samples[sample.Name] = sample
+
+	for _, em := range elasticMetrics[sample.Name] {
+		if !em.matches(attributes) {
+			continue
+		}
+		elasticAttributes := pcommon.NewMap()
+		if em.nameAttribute != "" {
+			v, ok := attributes.Get(em.nameAttribute)
+			if !ok {
+				continue
+			}
+			elasticAttributes.PutStr("name", v.AsString())
+		}
+		m := ms.get(timestamp, elasticAttributes, true)
+		if existing, ok := m.samples[em.name]; ok {
+			if existing.Histogram == nil && existing.Summary == nil {
+				existing.Value += sample.Value
+			}
+			continue
+		}
+		elasticSample := sample.CloneVT()
+		elasticSample.Name = em.name
+		m.samples[em.name] = elasticSample
+	}
 }
 
-func (ms metricsets) upsertOne(timestamp time.Time, attributes pcommon.Map, sample *modelpb.MetricsetSample) {
+// get returns the metricset with the given timestamp and labels, creating
+// it if it does not exist. If elastic is true, the metricset is one which
+// holds Elastic APM metrics copied from OpenTelemetry metrics.
+func (ms metricsets) get(timestamp time.Time, attributes pcommon.Map, elastic bool) metricset {
 	var signatureBuilder strings.Builder
 	attributes.Range(func(k string, v pcommon.Value) bool {
 		signatureBuilder.WriteString(k)
 		signatureBuilder.WriteString(v.AsString())
 		return true
 	})
-	key := metricsetKey{timestamp: timestamp, signature: signatureBuilder.String()}
+	key := metricsetKey{timestamp: timestamp, signature: signatureBuilder.String(), elastic: elastic}
 
 	m, ok := ms[key]
 	if !ok {
@@ -320,5 +573,5 @@ func (ms metricsets) upsertOne(timestamp time.Time, attributes pcommon.Map, samp
 		}
 		ms[key] = m
 	}
-	m.samples[sample.Name] = sample
+	return m
 }
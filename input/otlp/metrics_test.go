@@ -36,6 +36,7 @@ package otlp_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"sort"
@@ -44,6 +45,7 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/pdata/pcommon"
@@ -54,6 +56,7 @@ import (
 
 	"github.com/elastic/apm-data/input/otlp"
 	"github.com/elastic/apm-data/model/modelpb"
+	"github.com/elastic/apm-data/model/modelprocessor"
 )
 
 func TestConsumeMetrics(t *testing.T) {
@@ -89,6 +92,7 @@ func TestConsumeMetrics(t *testing.T) {
 	gaugeDP3.Attributes().PutStr("k", "v2")
 
 	sum := appendMetric("sum_metric").SetEmptySum()
+	sum.SetIsMonotonic(true)
 	sumDP0 := sum.DataPoints().AppendEmpty()
 	sumDP0.SetTimestamp(pcommon.NewTimestampFromTime(timestamp0))
 	sumDP0.SetIntValue(7)
@@ -220,6 +224,412 @@ func TestConsumeMetrics(t *testing.T) {
 	eventsMatch(t, expected, events)
 }
 
+func TestConsumeMetricsExponentialHistogram(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	resourceMetrics := metrics.ResourceMetrics().AppendEmpty()
+	scopeMetrics := resourceMetrics.ScopeMetrics().AppendEmpty()
+	metricSlice := scopeMetrics.Metrics()
+	timestamp := time.Unix(123, 0).UTC()
+
+	appendHistogram := func(name string, scale int32) pmetric.ExponentialHistogramDataPoint {
+		metric := metricSlice.AppendEmpty()
+		metric.SetName(name)
+		dp := metric.SetEmptyExponentialHistogram().DataPoints().AppendEmpty()
+		dp.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+		dp.SetScale(scale)
+		return dp
+	}
+
+	// At scale 0, base is 2 and bucket i has boundaries (2**i, 2**(i+1)].
+	dp := appendHistogram("histogram_scale0", 0)
+	dp.SetZeroCount(1)
+	dp.Positive().SetOffset(-1)
+	dp.Positive().BucketCounts().Append(2, 0, 3, 4) // (0.5,1], (1,2], (2,4], (4,8]
+	dp.Negative().SetOffset(1)
+	dp.Negative().BucketCounts().Append(5, 6) // [-4,-2), [-8,-4)
+
+	// At scale 1, base is sqrt(2).
+	dp = appendHistogram("histogram_scale1", 1)
+	dp.Positive().SetOffset(2)
+	dp.Positive().BucketCounts().Append(7) // (2, 2*sqrt(2)]
+
+	// At scale -1, base is 4.
+	dp = appendHistogram("histogram_scale-1", -1)
+	dp.Positive().BucketCounts().Append(8) // (1, 4]
+
+	appendHistogram("empty_histogram", 0)
+
+	// Invalid scales, and buckets with infinite boundaries, are dropped.
+	appendHistogram("invalid_scale", 21).SetZeroCount(1)
+	dp = appendHistogram("overflow", 0)
+	dp.Positive().SetOffset(1024)
+	dp.Positive().BucketCounts().Append(1)
+
+	events, stats := transformMetrics(t, metrics)
+	assert.Equal(t, int64(2), stats.UnsupportedMetricsDropped)
+
+	histogramSample := func(name string, counts []int64, values []float64) *modelpb.MetricsetSample {
+		return &modelpb.MetricsetSample{
+			Name:      name,
+			Type:      modelpb.MetricType_METRIC_TYPE_HISTOGRAM,
+			Histogram: &modelpb.Histogram{Counts: counts, Values: values},
+		}
+	}
+	service := modelpb.Service{Name: "unknown", Language: &modelpb.Language{Name: "unknown"}}
+	agent := modelpb.Agent{Name: "otlp", Version: "unknown"}
+	eventsMatch(t, []*modelpb.APMEvent{{
+		Agent:     &agent,
+		Service:   &service,
+		Timestamp: timestamppb.New(timestamp),
+		Processor: modelpb.MetricsetProcessor(),
+		Metricset: &modelpb.Metricset{
+			Name: "app",
+			Samples: []*modelpb.MetricsetSample{
+				histogramSample("empty_histogram", []int64{}, []float64{}),
+				histogramSample("histogram_scale-1", []int64{8}, []float64{2.5}),
+				histogramSample("histogram_scale0",
+					[]int64{6, 5, 1, 2, 3, 4},
+					[]float64{-6, -3, 0, 0.75, 3, 6},
+				),
+				histogramSample("histogram_scale1", []int64{7}, []float64{1 + math.Sqrt2}),
+			},
+		},
+	}}, events)
+}
+
+func TestConsumeMetricsExemplars(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	resourceMetrics := metrics.ResourceMetrics().AppendEmpty()
+	metricSlice := resourceMetrics.ScopeMetrics().AppendEmpty().Metrics()
+	timestamp := time.Unix(123, 0).UTC()
+	exemplarTimestamp := time.Unix(122, 0).UTC()
+
+	metric := metricSlice.AppendEmpty()
+	metric.SetName("histogram")
+	hdp := metric.SetEmptyHistogram().DataPoints().AppendEmpty()
+	hdp.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	hdp.BucketCounts().Append(1, 1)
+	hdp.ExplicitBounds().Append(1)
+	exemplar := hdp.Exemplars().AppendEmpty()
+	exemplar.SetTimestamp(pcommon.NewTimestampFromTime(exemplarTimestamp))
+	exemplar.SetDoubleValue(1.5)
+	exemplar.SetTraceID(pcommon.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	exemplar.SetSpanID(pcommon.SpanID{1, 2, 3, 4, 5, 6, 7, 8})
+	exemplar.FilteredAttributes().PutStr("http.route", "/users")
+	exemplar.FilteredAttributes().PutInt("user.count", 3)
+	exemplar = hdp.Exemplars().AppendEmpty()
+	exemplar.SetDoubleValue(math.NaN()) // dropped
+
+	metric = metricSlice.AppendEmpty()
+	metric.SetName("sum")
+	sum := metric.SetEmptySum()
+	sum.SetIsMonotonic(true)
+	sdp := sum.DataPoints().AppendEmpty()
+	sdp.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	sdp.SetIntValue(10)
+	exemplar = sdp.Exemplars().AppendEmpty()
+	exemplar.SetTimestamp(pcommon.NewTimestampFromTime(exemplarTimestamp))
+	exemplar.SetIntValue(2)
+
+	events, _ := transformMetrics(t, metrics)
+	require.Len(t, events, 1)
+	samples := make(map[string]*modelpb.MetricsetSample)
+	for _, sample := range events[0].Metricset.Samples {
+		samples[sample.Name] = sample
+	}
+	assert.Empty(t, cmp.Diff([]*modelpb.Exemplar{{
+		Timestamp:     timestamppb.New(exemplarTimestamp),
+		Value:         1.5,
+		TraceId:       "0102030405060708090a0b0c0d0e0f10",
+		SpanId:        "0102030405060708",
+		Labels:        modelpb.Labels{"http.route": {Value: "/users"}},
+		NumericLabels: modelpb.NumericLabels{"user.count": {Value: 3}},
+	}}, samples["histogram"].Exemplars, protocmp.Transform()))
+	assert.Empty(t, cmp.Diff([]*modelpb.Exemplar{{
+		Timestamp: timestamppb.New(exemplarTimestamp),
+		Value:     2,
+	}}, samples["sum"].Exemplars, protocmp.Transform()))
+}
+
+func TestConsumeMetricsSumTemporality(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	metricSlice := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	timestamp := time.Unix(123, 0).UTC()
+	appendSum := func(name string, temporality pmetric.AggregationTemporality, monotonic bool) {
+		metric := metricSlice.AppendEmpty()
+		metric.SetName(name)
+		sum := metric.SetEmptySum()
+		sum.SetAggregationTemporality(temporality)
+		sum.SetIsMonotonic(monotonic)
+		dp := sum.DataPoints().AppendEmpty()
+		dp.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+		dp.SetIntValue(1)
+	}
+	appendSum("delta_counter", pmetric.AggregationTemporalityDelta, true)
+	appendSum("cumulative_counter", pmetric.AggregationTemporalityCumulative, true)
+	appendSum("updown_counter", pmetric.AggregationTemporalityCumulative, false)
+
+	events, _ := transformMetrics(t, metrics)
+	require.Len(t, events, 1)
+	samples := events[0].Metricset.Samples
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Name < samples[j].Name })
+	assert.Empty(t, cmp.Diff([]*modelpb.MetricsetSample{{
+		Name:        "cumulative_counter",
+		Type:        modelpb.MetricType_METRIC_TYPE_COUNTER,
+		Temporality: modelpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+		Value:       1,
+	}, {
+		Name:        "delta_counter",
+		Type:        modelpb.MetricType_METRIC_TYPE_COUNTER,
+		Temporality: modelpb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+		Value:       1,
+	}, {
+		Name:        "updown_counter",
+		Type:        modelpb.MetricType_METRIC_TYPE_GAUGE,
+		Temporality: modelpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+		Value:       1,
+	}}, samples, protocmp.Transform()))
+}
+
+// sumDataPoint describes a sum data point for testing temporality conversion.
+type sumDataPoint struct {
+	start, timestamp int64 // seconds since the epoch
+	value            float64
+	attributes       map[string]any
+}
+
+// convertSums sends each data point in its own request to a consumer
+// configured to convert sums to the given temporality, and returns the
+// values recorded for the data points which were not dropped.
+//
+// Timestamps are offsets in seconds from when the consumer is created.
+func convertSums(
+	t *testing.T,
+	to modelpb.AggregationTemporality,
+	from pmetric.AggregationTemporality,
+	monotonic bool,
+	dps ...sumDataPoint,
+) []float64 {
+	var batches []*modelpb.Batch
+	consumer := otlp.NewConsumer(otlp.ConsumerConfig{
+		Processor:      batchRecorderBatchProcessor(&batches),
+		Semaphore:      semaphore.NewWeighted(1),
+		SumTemporality: to,
+	})
+	base := time.Now().Unix() + 1
+	for _, dp := range dps {
+		metrics := pmetric.NewMetrics()
+		resourceMetrics := metrics.ResourceMetrics().AppendEmpty()
+		resourceMetrics.Resource().Attributes().PutStr("service.name", "service")
+		scopeMetrics := resourceMetrics.ScopeMetrics().AppendEmpty()
+		scopeMetrics.Scope().SetName("scope")
+		metric := scopeMetrics.Metrics().AppendEmpty()
+		metric.SetName("sum")
+		sum := metric.SetEmptySum()
+		sum.SetAggregationTemporality(from)
+		sum.SetIsMonotonic(monotonic)
+		otelDP := sum.DataPoints().AppendEmpty()
+		if dp.start != 0 {
+			otelDP.SetStartTimestamp(pcommon.NewTimestampFromTime(time.Unix(base+dp.start, 0)))
+		}
+		otelDP.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(base+dp.timestamp, 0)))
+		otelDP.SetDoubleValue(dp.value)
+		require.NoError(t, otelDP.Attributes().FromRaw(dp.attributes))
+		require.NoError(t, consumer.ConsumeMetrics(context.Background(), metrics))
+	}
+	if !monotonic {
+		// Non-monotonic sums are not converted.
+		to = modelpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+		if from == pmetric.AggregationTemporalityDelta {
+			to = modelpb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA
+		}
+	}
+	var values []float64
+	for _, batch := range batches {
+		for _, event := range *batch {
+			for _, sample := range event.Metricset.Samples {
+				assert.Equal(t, to, sample.Temporality)
+				values = append(values, sample.Value)
+			}
+		}
+	}
+	return values
+}
+
+func TestConsumeMetricsCumulativeToDelta(t *testing.T) {
+	delta := modelpb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA
+	cumulative := pmetric.AggregationTemporalityCumulative
+
+	values := convertSums(t, delta, cumulative, true,
+		sumDataPoint{start: 100, timestamp: 110, value: 10}, // delta since start
+		sumDataPoint{start: 100, timestamp: 120, value: 15},
+		sumDataPoint{start: 100, timestamp: 120, value: 16}, // not newer, dropped
+		sumDataPoint{start: 100, timestamp: 130, value: 12}, // decreased, reset
+		sumDataPoint{start: 125, timestamp: 140, value: 3},  // new start, reset
+		sumDataPoint{start: 125, timestamp: 150, value: 7},
+	)
+	assert.Equal(t, []float64{10, 5, 12, 3, 4}, values)
+
+	// Without start timestamps, the first data point is dropped.
+	values = convertSums(t, delta, cumulative, true,
+		sumDataPoint{timestamp: 110, value: 10},
+		sumDataPoint{timestamp: 120, value: 15},
+		sumDataPoint{timestamp: 130, value: 21},
+	)
+	assert.Equal(t, []float64{5, 6}, values)
+
+	// Series which started before the consumer was created may have been
+	// recorded before, e.g. before a restart, so the first data point is
+	// dropped rather than recording the whole total as a delta.
+	values = convertSums(t, delta, cumulative, true,
+		sumDataPoint{start: -100, timestamp: 110, value: 10},
+		sumDataPoint{start: -100, timestamp: 120, value: 15},
+		sumDataPoint{start: 115, timestamp: 130, value: 3}, // new start, reset
+	)
+	assert.Equal(t, []float64{5, 3}, values)
+
+	// Series are tracked by attributes.
+	values = convertSums(t, delta, cumulative, true,
+		sumDataPoint{start: 100, timestamp: 110, value: 10, attributes: map[string]any{"k": "a"}},
+		sumDataPoint{start: 100, timestamp: 110, value: 20, attributes: map[string]any{"k": "b"}},
+		sumDataPoint{start: 100, timestamp: 120, value: 14, attributes: map[string]any{"k": "a"}},
+		sumDataPoint{start: 100, timestamp: 120, value: 25, attributes: map[string]any{"k": "b"}},
+	)
+	assert.Equal(t, []float64{10, 20, 4, 5}, values)
+
+	// Non-monotonic sums are left as they are.
+	values = convertSums(t, delta, cumulative, false,
+		sumDataPoint{start: -100, timestamp: 110, value: 10},
+		sumDataPoint{start: -100, timestamp: 120, value: 4},
+	)
+	assert.Equal(t, []float64{10, 4}, values)
+}
+
+func TestConsumeMetricsDeltaToCumulative(t *testing.T) {
+	values := convertSums(t,
+		modelpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+		pmetric.AggregationTemporalityDelta, true,
+		sumDataPoint{start: 100, timestamp: 110, value: 1},
+		sumDataPoint{start: 110, timestamp: 120, value: 2},
+		sumDataPoint{start: 125, timestamp: 130, value: 3}, // gaps are accumulated
+		sumDataPoint{timestamp: 140, value: 4},             // no start time
+		sumDataPoint{start: 100, timestamp: 150, value: 5}, // overlap, reset
+		sumDataPoint{start: 150, timestamp: 160, value: 6},
+	)
+	assert.Equal(t, []float64{1, 3, 6, 10, 5, 11}, values)
+}
+
+func TestConsumeMetricsUnits(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	metricSlice := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	timestamp := pcommon.NewTimestampFromTime(time.Unix(123, 0))
+	appendGauge := func(name, unit string, value float64) {
+		metric := metricSlice.AppendEmpty()
+		metric.SetName(name)
+		metric.SetUnit(unit)
+		dp := metric.SetEmptyGauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(timestamp)
+		dp.SetDoubleValue(value)
+	}
+	appendGauge("bytes", "By", 1)
+	appendGauge("kibibytes", "KiBy", 2)
+	appendGauge("nanoseconds", "ns", 3e6)
+	appendGauge("milliseconds", "ms", 4)
+	appendGauge("seconds", "s", 5)
+	appendGauge("percent", "%", 50)
+	appendGauge("ratio", "1", 0.5)
+	appendGauge("requests", "{requests}", 6)
+	appendGauge("packets", "{packets}", 9)
+	appendGauge("unknown", "furlongs", 7)
+	appendGauge("none", "", 8)
+
+	summary := metricSlice.AppendEmpty()
+	summary.SetName("summary")
+	summary.SetUnit("s")
+	summaryDP := summary.SetEmptySummary().DataPoints().AppendEmpty()
+	summaryDP.SetTimestamp(timestamp)
+	summaryDP.SetCount(2)
+	summaryDP.SetSum(1.5)
+
+	histogram := metricSlice.AppendEmpty()
+	histogram.SetName("histogram")
+	histogram.SetUnit("s")
+	histogramDP := histogram.SetEmptyHistogram().DataPoints().AppendEmpty()
+	histogramDP.SetTimestamp(timestamp)
+	histogramDP.BucketCounts().Append(1, 2)
+	histogramDP.ExplicitBounds().Append(1)
+
+	gauge := func(name, unit string, value float64) *modelpb.MetricsetSample {
+		return &modelpb.MetricsetSample{Name: name, Unit: unit, Type: modelpb.MetricType_METRIC_TYPE_GAUGE, Value: value}
+	}
+	for _, test := range []struct {
+		normalize bool
+		expected  []*modelpb.MetricsetSample
+	}{{
+		normalize: false,
+		expected: []*modelpb.MetricsetSample{
+			gauge("bytes", "byte", 1),
+			gauge("kibibytes", "KiBy", 2),
+			gauge("nanoseconds", "nanos", 3e6),
+			gauge("milliseconds", "ms", 4),
+			gauge("seconds", "s", 5),
+			gauge("percent", "%", 50),
+			gauge("ratio", "", 0.5),
+			gauge("requests", "", 6),
+			gauge("packets", "{packets}", 9),
+			gauge("unknown", "furlongs", 7),
+			gauge("none", "", 8),
+			{
+				Name: "summary", Unit: "s", Type: modelpb.MetricType_METRIC_TYPE_SUMMARY,
+				Summary: &modelpb.SummaryMetric{Count: 2, Sum: 1.5},
+			},
+			{
+				Name: "histogram", Unit: "s", Type: modelpb.MetricType_METRIC_TYPE_HISTOGRAM,
+				Histogram: &modelpb.Histogram{Counts: []int64{1, 2}, Values: []float64{0.5, 1}},
+			},
+		},
+	}, {
+		normalize: true,
+		expected: []*modelpb.MetricsetSample{
+			gauge("bytes", "byte", 1),
+			gauge("kibibytes", "byte", 2048),
+			gauge("nanoseconds", "ms", 3),
+			gauge("milliseconds", "ms", 4),
+			gauge("seconds", "ms", 5000),
+			gauge("percent", "percent", 0.5),
+			gauge("ratio", "", 0.5),
+			gauge("requests", "", 6),
+			gauge("packets", "{packets}", 9),
+			gauge("unknown", "furlongs", 7),
+			gauge("none", "", 8),
+			{
+				Name: "summary", Unit: "ms", Type: modelpb.MetricType_METRIC_TYPE_SUMMARY,
+				Summary: &modelpb.SummaryMetric{Count: 2, Sum: 1500},
+			},
+			{
+				Name: "histogram", Unit: "ms", Type: modelpb.MetricType_METRIC_TYPE_HISTOGRAM,
+				Histogram: &modelpb.Histogram{Counts: []int64{1, 2}, Values: []float64{500, 1000}},
+			},
+		},
+	}} {
+		t.Run(fmt.Sprintf("normalize=%v", test.normalize), func(t *testing.T) {
+			var batches []*modelpb.Batch
+			consumer := otlp.NewConsumer(otlp.ConsumerConfig{
+				Processor:            batchRecorderBatchProcessor(&batches),
+				Semaphore:            semaphore.NewWeighted(1),
+				NormalizeMetricUnits: test.normalize,
+			})
+			require.NoError(t, consumer.ConsumeMetrics(context.Background(), metrics))
+			require.Len(t, batches, 1)
+			require.Len(t, *batches[0], 1)
+			samples := (*batches[0])[0].Metricset.Samples
+			assert.Empty(t, cmp.Diff(test.expected, samples, protocmp.Transform(),
+				cmpopts.SortSlices(func(a, b *modelpb.MetricsetSample) bool { return a.Name < b.Name }),
+			))
+		})
+	}
+}
+
 func TestConsumeMetricsSemaphore(t *testing.T) {
 	metrics := pmetric.NewMetrics()
 	var batches []*modelpb.Batch
@@ -250,6 +660,35 @@ func TestConsumeMetricsSemaphore(t *testing.T) {
 	assert.NoError(t, consumer.ConsumeMetrics(context.Background(), metrics))
 }
 
+func TestConsumeMetricsAsync(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("gauge_metric")
+	metric.SetEmptyGauge().DataPoints().AppendEmpty().SetIntValue(1)
+
+	batchCh := make(chan *modelpb.Batch, 1)
+	sem := &fakeSemaphore{available: 1, released: make(chan struct{}, 1)}
+	consumer := otlp.NewConsumer(otlp.ConsumerConfig{
+		Processor: modelpb.ProcessBatchFunc(func(ctx context.Context, batch *modelpb.Batch) error {
+			batchCh <- batch
+			return nil
+		}),
+		Semaphore: sem,
+		Async:     true,
+	})
+
+	result, err := consumer.ConsumeMetricsWithResult(context.Background(), metrics)
+	require.NoError(t, err)
+	assert.Equal(t, otlp.ConsumeMetricsResult{AcceptedDataPoints: 1}, result)
+	batch := <-batchCh
+	<-sem.released
+	require.Len(t, *batch, 1)
+	assert.Equal(t, "gauge_metric", (*batch)[0].Metricset.Samples[0].Name)
+
+	sem.available = 0
+	assert.Equal(t, otlp.ErrQueueFull, consumer.ConsumeMetrics(context.Background(), metrics))
+}
+
 func TestConsumeMetricsNaN(t *testing.T) {
 	timestamp := time.Unix(123, 0).UTC()
 	metrics := pmetric.NewMetrics()
@@ -271,6 +710,134 @@ func TestConsumeMetricsNaN(t *testing.T) {
 	assert.Empty(t, events)
 }
 
+func TestConsumeMetricsWithResult(t *testing.T) {
+	timestamp := time.Unix(123, 0).UTC()
+	metrics := pmetric.NewMetrics()
+	resourceMetrics := metrics.ResourceMetrics().AppendEmpty()
+	scopeMetrics := resourceMetrics.ScopeMetrics().AppendEmpty()
+	metricSlice := scopeMetrics.Metrics()
+
+	gaugeMetric := metricSlice.AppendEmpty()
+	gaugeMetric.SetName("gauge")
+	gauge := gaugeMetric.SetEmptyGauge()
+	for _, value := range []float64{1, 2, math.NaN()} {
+		dp := gauge.DataPoints().AppendEmpty()
+		dp.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+		dp.SetDoubleValue(value)
+	}
+	histogram := metricSlice.AppendEmpty()
+	histogram.SetName("histogram")
+	dp := histogram.SetEmptyHistogram().DataPoints().AppendEmpty()
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	dp.BucketCounts().Append(1, 2, 3) // no explicit bounds
+	metricSlice.AppendEmpty().SetName("empty")
+
+	var batches []*modelpb.Batch
+	consumer := otlp.NewConsumer(otlp.ConsumerConfig{
+		Processor: batchRecorderBatchProcessor(&batches),
+		Semaphore: semaphore.NewWeighted(100),
+	})
+	result, err := consumer.ConsumeMetricsWithResult(context.Background(), metrics)
+	require.NoError(t, err)
+	assert.Equal(t, otlp.ConsumeMetricsResult{
+		AcceptedDataPoints: 2,
+		RejectedDataPoints: 3,
+		ErrorMessage:       "invalid histogram buckets: 1; invalid number value: 1; unsupported metric type: 1",
+	}, result)
+
+	partialSuccess := result.ExportResponse().PartialSuccess()
+	assert.Equal(t, int64(3), partialSuccess.RejectedDataPoints())
+	assert.Equal(t, result.ErrorMessage, partialSuccess.ErrorMessage())
+
+	stats := consumer.Stats()
+	assert.Equal(t, int64(2), stats.AcceptedDataPoints)
+	assert.Equal(t, int64(3), stats.RejectedDataPoints)
+}
+
+func TestConsumeMetricsWithResultProcessError(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	resourceMetrics := metrics.ResourceMetrics().AppendEmpty()
+	for i := 0; i < 2; i++ {
+		metric := resourceMetrics.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+		metric.SetName("gauge")
+		gauge := metric.SetEmptyGauge()
+		for j := 0; j < 3; j++ {
+			dp := gauge.DataPoints().AppendEmpty()
+			dp.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(123, 0)))
+			dp.Attributes().PutInt("j", int64(j))
+			dp.SetIntValue(1)
+		}
+	}
+
+	processErr := errors.New("queue full")
+	var batches int
+	consumer := otlp.NewConsumer(otlp.ConsumerConfig{
+		Processor: modelpb.ProcessBatchFunc(func(ctx context.Context, batch *modelpb.Batch) error {
+			if batches++; batches > 3 {
+				return processErr
+			}
+			return nil
+		}),
+		Semaphore:    semaphore.NewWeighted(100),
+		MaxBatchSize: 1,
+	})
+
+	// Data points are only counted as accepted once all metricsets
+	// for their scope have been processed.
+	result, err := consumer.ConsumeMetricsWithResult(context.Background(), metrics)
+	assert.Equal(t, processErr, err)
+	assert.Equal(t, otlp.ConsumeMetricsResult{AcceptedDataPoints: 3}, result)
+	assert.Equal(t, 4, batches)
+}
+
+func TestConsumeMetricsWithResultSumTemporality(t *testing.T) {
+	newMetrics := func(timestamps ...int64) pmetric.Metrics {
+		metrics := pmetric.NewMetrics()
+		metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+		metric.SetName("sum")
+		sum := metric.SetEmptySum()
+		sum.SetIsMonotonic(true)
+		sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		for _, timestamp := range timestamps {
+			dp := sum.DataPoints().AppendEmpty()
+			dp.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(timestamp, 0)))
+			dp.SetIntValue(timestamp)
+		}
+		return metrics
+	}
+
+	var batches []*modelpb.Batch
+	consumer := otlp.NewConsumer(otlp.ConsumerConfig{
+		Processor:      batchRecorderBatchProcessor(&batches),
+		Semaphore:      semaphore.NewWeighted(100),
+		SumTemporality: modelpb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+	})
+
+	// The first cumulative data point has no previous value from which
+	// to calculate a delta, so it is dropped, but not rejected.
+	result, err := consumer.ConsumeMetricsWithResult(context.Background(), newMetrics(123))
+	require.NoError(t, err)
+	assert.Equal(t, otlp.ConsumeMetricsResult{AcceptedDataPoints: 1}, result)
+	partialSuccess := result.ExportResponse().PartialSuccess()
+	assert.Zero(t, partialSuccess.RejectedDataPoints())
+	assert.Empty(t, partialSuccess.ErrorMessage())
+
+	// Data points which are not newer than the previous data point
+	// are rejected.
+	result, err = consumer.ConsumeMetricsWithResult(context.Background(), newMetrics(124, 124))
+	require.NoError(t, err)
+	assert.Equal(t, otlp.ConsumeMetricsResult{
+		AcceptedDataPoints: 1,
+		RejectedDataPoints: 1,
+		ErrorMessage:       "sum data point not newer than the previous data point: 1",
+	}, result)
+
+	require.Len(t, batches, 2)
+	assert.Empty(t, *batches[0])
+	require.Len(t, *batches[1], 1)
+	assert.Equal(t, float64(1), (*batches[1])[0].Metricset.Samples[0].Value)
+}
+
 func TestConsumeMetricsHostCPU(t *testing.T) {
 	metrics := pmetric.NewMetrics()
 	resourceMetrics := metrics.ResourceMetrics().AppendEmpty()
@@ -563,6 +1130,7 @@ func TestConsumeMetricsHostMemory(t *testing.T) {
 		"state": "used",
 	})
 	events, _ := transformMetrics(t, metrics)
+	// system.memory.usage is an up-down counter, so it is recorded as a gauge.
 	service := modelpb.Service{Name: "unknown", Language: &modelpb.Language{Name: "unknown"}}
 	agent := modelpb.Agent{Name: "otlp", Version: "unknown"}
 	expected := []*modelpb.APMEvent{{
@@ -576,7 +1144,7 @@ func TestConsumeMetricsHostMemory(t *testing.T) {
 			Samples: []*modelpb.MetricsetSample{
 				{
 					Name:  "system.memory.usage",
-					Type:  modelpb.MetricType_METRIC_TYPE_COUNTER,
+					Type:  modelpb.MetricType_METRIC_TYPE_GAUGE,
 					Value: 4773351424,
 				},
 			},
@@ -592,7 +1160,7 @@ func TestConsumeMetricsHostMemory(t *testing.T) {
 			Samples: []*modelpb.MetricsetSample{
 				{
 					Name:  "system.memory.usage",
-					Type:  modelpb.MetricType_METRIC_TYPE_COUNTER,
+					Type:  modelpb.MetricType_METRIC_TYPE_GAUGE,
 					Value: 3563778048,
 				},
 			},
@@ -662,6 +1230,37 @@ func TestConsumeMetrics_JVM(t *testing.T) {
 				},
 			},
 		},
+	}, {
+		Agent:     &agent,
+		Service:   &service,
+		Timestamp: timestamppb.New(timestamp),
+		Processor: modelpb.MetricsetProcessor(),
+		Metricset: &modelpb.Metricset{
+			Name: "app",
+			Samples: []*modelpb.MetricsetSample{
+				{
+					Name:  "jvm.memory.heap.max",
+					Type:  modelpb.MetricType_METRIC_TYPE_GAUGE,
+					Value: 20000,
+				},
+			},
+		},
+	}, {
+		Agent:     &agent,
+		Service:   &service,
+		Labels:    modelpb.Labels{"name": {Value: "G1 Eden Space"}},
+		Timestamp: timestamppb.New(timestamp),
+		Processor: modelpb.MetricsetProcessor(),
+		Metricset: &modelpb.Metricset{
+			Name: "app",
+			Samples: []*modelpb.MetricsetSample{
+				{
+					Name:  "jvm.memory.heap.pool.max",
+					Type:  modelpb.MetricType_METRIC_TYPE_GAUGE,
+					Value: 20000,
+				},
+			},
+		},
 	}, {
 		Agent:     &agent,
 		Service:   &service,
@@ -686,6 +1285,97 @@ func TestConsumeMetrics_JVM(t *testing.T) {
 	eventsMatch(t, expected, events)
 }
 
+func TestConsumeMetricsElasticRuntimeMetrics(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	resourceMetrics := metrics.ResourceMetrics().AppendEmpty()
+	metricSlice := resourceMetrics.ScopeMetrics().AppendEmpty().Metrics()
+	timestamp := time.Unix(123, 0).UTC()
+
+	addSum := func(name string, values []int64, attributes []map[string]interface{}) {
+		metric := metricSlice.AppendEmpty()
+		metric.SetName(name)
+		sum := metric.SetEmptySum()
+		for i, value := range values {
+			dp := sum.DataPoints().AppendEmpty()
+			dp.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+			dp.SetIntValue(value)
+			dp.Attributes().FromRaw(attributes[i])
+		}
+	}
+	addSum("process.runtime.jvm.memory.usage", []int64{10, 20, 5, 1}, []map[string]interface{}{
+		{"type": "heap", "pool": "G1 Eden Space"},
+		{"type": "heap", "pool": "G1 Old Gen"},
+		{"type": "non_heap", "pool": "Metaspace"},
+		{"pool": "unknown"},
+	})
+	addSum("process.runtime.jvm.threads.count", []int64{3, 4}, []map[string]interface{}{
+		{"daemon": true},
+		{"daemon": false},
+	})
+	addSum("process.runtime.go.goroutines", []int64{7}, []map[string]interface{}{{}})
+
+	events, _ := transformMetrics(t, metrics)
+	samples := make(map[string]map[string]float64)
+	for _, event := range events {
+		for _, sample := range event.Metricset.Samples {
+			if !modelprocessor.IsInternalMetricName(sample.Name) {
+				continue
+			}
+			if samples[sample.Name] == nil {
+				samples[sample.Name] = make(map[string]float64)
+			}
+			samples[sample.Name][event.Labels["name"].GetValue()] = sample.Value
+		}
+	}
+	assert.Equal(t, map[string]map[string]float64{
+		"jvm.memory.heap.used": {"": 30},
+		"jvm.memory.heap.pool.used": {
+			"G1 Eden Space": 10,
+			"G1 Old Gen":    20,
+		},
+		"jvm.memory.non_heap.used": {"": 5},
+		"jvm.thread.count":         {"": 7},
+		"golang.goroutines":        {"": 7},
+	}, samples)
+}
+
+func TestConsumeMetricsElasticRuntimeMetricsDataStream(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	resourceMetrics := metrics.ResourceMetrics().AppendEmpty()
+	resourceMetrics.Resource().Attributes().PutStr("service.name", "service")
+	metricSlice := resourceMetrics.ScopeMetrics().AppendEmpty().Metrics()
+	timestamp := pcommon.NewTimestampFromTime(time.Unix(123, 0))
+	for _, name := range []string{"process.runtime.go.goroutines", "process.runtime.go.gc.count"} {
+		metric := metricSlice.AppendEmpty()
+		metric.SetName(name)
+		dp := metric.SetEmptySum().DataPoints().AppendEmpty()
+		dp.SetTimestamp(timestamp)
+		dp.SetIntValue(1)
+	}
+
+	// The Elastic APM metrics are recorded in their own metricset, even
+	// though they have the same timestamp and attributes as the originals,
+	// so they are routed to the internal metrics data stream.
+	events, _ := transformMetrics(t, metrics)
+	batch := modelpb.Batch(events)
+	processor := modelprocessor.SetDataStream{Namespace: "default"}
+	require.NoError(t, processor.ProcessBatch(context.Background(), &batch))
+
+	datasets := make(map[string][]string)
+	for _, event := range batch {
+		for _, sample := range event.Metricset.Samples {
+			datasets[event.DataStream.Dataset] = append(datasets[event.DataStream.Dataset], sample.Name)
+		}
+	}
+	for _, names := range datasets {
+		sort.Strings(names)
+	}
+	assert.Equal(t, map[string][]string{
+		"apm.internal":    {"golang.goroutines", "golang.heap.gc.total_count"},
+		"apm.app.service": {"process.runtime.go.gc.count", "process.runtime.go.goroutines"},
+	}, datasets)
+}
+
 func TestConsumeMetricsExportTimestamp(t *testing.T) {
 	metrics := pmetric.NewMetrics()
 	resourceMetrics := metrics.ResourceMetrics().AppendEmpty()
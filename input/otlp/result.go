@@ -0,0 +1,160 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package otlp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+)
+
+// Reasons for rejecting metric data points.
+const (
+	rejectedUnsupportedMetricType       = "unsupported metric type"
+	rejectedInvalidNumberValue          = "invalid number value"
+	rejectedInvalidHistogram            = "invalid histogram buckets"
+	rejectedInvalidExponentialHistogram = "invalid exponential histogram scale or buckets"
+	rejectedSumOutOfOrder               = "sum data point not newer than the previous data point"
+)
+
+// ConsumeTracesResult holds the result of consuming OTLP trace data.
+type ConsumeTracesResult struct {
+	// AcceptedSpans holds the number of spans accepted.
+	AcceptedSpans int64
+
+	// RejectedSpans holds the number of spans rejected.
+	//
+	// Spans are not currently rejected during conversion, so this is
+	// always zero; if processing fails, an error is returned instead.
+	RejectedSpans int64
+
+	// ErrorMessage describes why spans were rejected.
+	// It is empty if no spans were rejected.
+	ErrorMessage string
+}
+
+// ExportResponse returns an OTLP export response for r, reporting any
+// rejected spans as a partial success.
+func (r ConsumeTracesResult) ExportResponse() ptraceotlp.ExportResponse {
+	resp := ptraceotlp.NewExportResponse()
+	if r.RejectedSpans > 0 || r.ErrorMessage != "" {
+		resp.PartialSuccess().SetRejectedSpans(r.RejectedSpans)
+		resp.PartialSuccess().SetErrorMessage(r.ErrorMessage)
+	}
+	return resp
+}
+
+// ConsumeMetricsResult holds the result of consuming OTLP metrics data.
+type ConsumeMetricsResult struct {
+	// AcceptedDataPoints holds the number of data points accepted.
+	AcceptedDataPoints int64
+
+	// RejectedDataPoints holds the number of data points rejected.
+	// Metrics of unsupported types are each counted as one rejected
+	// data point.
+	RejectedDataPoints int64
+
+	// ErrorMessage describes why data points were rejected.
+	// It is empty if no data points were rejected.
+	ErrorMessage string
+}
+
+// ExportResponse returns an OTLP export response for r, reporting any
+// rejected data points as a partial success.
+func (r ConsumeMetricsResult) ExportResponse() pmetricotlp.ExportResponse {
+	resp := pmetricotlp.NewExportResponse()
+	if r.RejectedDataPoints > 0 || r.ErrorMessage != "" {
+		resp.PartialSuccess().SetRejectedDataPoints(r.RejectedDataPoints)
+		resp.PartialSuccess().SetErrorMessage(r.ErrorMessage)
+	}
+	return resp
+}
+
+// ConsumeLogsResult holds the result of consuming OTLP logs data.
+type ConsumeLogsResult struct {
+	// AcceptedLogRecords holds the number of log records accepted.
+	AcceptedLogRecords int64
+
+	// RejectedLogRecords holds the number of log records rejected.
+	//
+	// Log records are not currently rejected during conversion, so this
+	// is always zero; if processing fails, an error is returned instead.
+	RejectedLogRecords int64
+
+	// ErrorMessage describes why log records were rejected.
+	// It is empty if no log records were rejected.
+	ErrorMessage string
+}
+
+// ExportResponse returns an OTLP export response for r, reporting any
+// rejected log records as a partial success.
+func (r ConsumeLogsResult) ExportResponse() plogotlp.ExportResponse {
+	resp := plogotlp.NewExportResponse()
+	if r.RejectedLogRecords > 0 || r.ErrorMessage != "" {
+		resp.PartialSuccess().SetRejectedLogRecords(r.RejectedLogRecords)
+		resp.PartialSuccess().SetErrorMessage(r.ErrorMessage)
+	}
+	return resp
+}
+
+// consumeResult accumulates the number of items accepted and rejected
+// while converting a payload, along with the reasons for rejection.
+type consumeResult struct {
+	accepted int64
+	rejected map[string]int64
+}
+
+func (r *consumeResult) reject(reason string) {
+	if r.rejected == nil {
+		r.rejected = make(map[string]int64)
+	}
+	r.rejected[reason]++
+}
+
+func (r *consumeResult) rejectedCount() int64 {
+	var n int64
+	for _, count := range r.rejected {
+		n += count
+	}
+	return n
+}
+
+// errorMessage returns a description of the reasons for rejection,
+// ordered by reason, or an empty string if nothing was rejected.
+func (r *consumeResult) errorMessage() string {
+	if len(r.rejected) == 0 {
+		return ""
+	}
+	reasons := make([]string, 0, len(r.rejected))
+	for reason := range r.rejected {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	var sb strings.Builder
+	for i, reason := range reasons {
+		if i > 0 {
+			sb.WriteString("; ")
+		}
+		fmt.Fprintf(&sb, "%s: %d", reason, r.rejected[reason])
+	}
+	return sb.String()
+}
@@ -0,0 +1,92 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package otlp
+
+import (
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// elasticMetric describes an Elastic APM metric which is copied from
+// a well-known OpenTelemetry metric.
+type elasticMetric struct {
+	// name is the Elastic APM metric name.
+	name string
+
+	// match holds attribute values that a data point must have for
+	// it to be copied.
+	match map[string]string
+
+	// nameAttribute, if non-empty, is the attribute whose value is
+	// recorded in the "name" label of the Elastic APM metric.
+	//
+	// All other attributes are dropped, and the values of data points
+	// with the same timestamp and name are summed.
+	nameAttribute string
+}
+
+func (m elasticMetric) matches(attributes pcommon.Map) bool {
+	for k, want := range m.match {
+		v, ok := attributes.Get(k)
+		if !ok || v.AsString() != want {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	jvmHeap    = map[string]string{"type": "heap"}
+	jvmNonHeap = map[string]string{"type": "non_heap"}
+)
+
+// elasticMetrics maps OpenTelemetry runtime metric names to the Elastic
+// APM metrics they are copied to, so that the Elastic runtime dashboards
+// work for services instrumented with OpenTelemetry. All Elastic APM
+// metric names must be listed in modelprocessor.IsInternalMetricName.
+//
+// See https://github.com/open-telemetry/opentelemetry-specification/blob/main/specification/metrics/semantic_conventions/runtime-environment-metrics.md
+// and https://pkg.go.dev/go.opentelemetry.io/contrib/instrumentation/runtime.
+var elasticMetrics = map[string][]elasticMetric{
+	"process.runtime.jvm.memory.usage": {
+		{name: "jvm.memory.heap.used", match: jvmHeap},
+		{name: "jvm.memory.heap.pool.used", match: jvmHeap, nameAttribute: "pool"},
+		{name: "jvm.memory.non_heap.used", match: jvmNonHeap},
+	},
+	"process.runtime.jvm.memory.committed": {
+		{name: "jvm.memory.heap.committed", match: jvmHeap},
+		{name: "jvm.memory.heap.pool.committed", match: jvmHeap, nameAttribute: "pool"},
+		{name: "jvm.memory.non_heap.committed", match: jvmNonHeap},
+	},
+	"process.runtime.jvm.memory.limit": {
+		{name: "jvm.memory.heap.max", match: jvmHeap},
+		{name: "jvm.memory.heap.pool.max", match: jvmHeap, nameAttribute: "pool"},
+		{name: "jvm.memory.non_heap.max", match: jvmNonHeap},
+	},
+	"process.runtime.jvm.threads.count":          {{name: "jvm.thread.count"}},
+	"process.runtime.jvm.cpu.utilization":        {{name: "system.process.cpu.total.norm.pct"}},
+	"process.runtime.jvm.system.cpu.utilization": {{name: "system.cpu.total.norm.pct"}},
+
+	"process.runtime.go.goroutines":        {{name: "golang.goroutines"}},
+	"process.runtime.go.gc.count":          {{name: "golang.heap.gc.total_count"}},
+	"process.runtime.go.mem.heap_alloc":    {{name: "golang.heap.allocations.allocated"}},
+	"process.runtime.go.mem.heap_idle":     {{name: "golang.heap.allocations.idle"}},
+	"process.runtime.go.mem.heap_inuse":    {{name: "golang.heap.allocations.active"}},
+	"process.runtime.go.mem.heap_objects":  {{name: "golang.heap.allocations.objects"}},
+	"process.runtime.go.mem.heap_released": {{name: "golang.heap.system.released"}},
+	"process.runtime.go.mem.heap_sys":      {{name: "golang.heap.system.obtained"}},
+}
@@ -0,0 +1,497 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package otlp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/apm-data/model/modelpb"
+)
+
+var (
+	pythonStacktraceFileRegexp  = regexp.MustCompile(`^  File "(.*)", line ([0-9]+)(?:, in (.*))?$`)
+	nodeStacktraceLocRegexp     = regexp.MustCompile(`^(.*):([0-9]+):([0-9]+)$`)
+	dotnetStacktraceAtRegexp    = regexp.MustCompile(`^at (.+?)(?: in (.*):line ([0-9]+))?$`)
+	rubyStacktraceHeaderRegexp  = regexp.MustCompile("^(.+?):([0-9]+):in [`'](.*?)': (.*) \\(([^()]+)\\)$")
+	rubyStacktraceFromRegexp    = regexp.MustCompile("^\tfrom (.+?):([0-9]+)(?::in [`'](.*)')?$")
+	phpStacktraceHeaderRegexp   = regexp.MustCompile(`^(?:Next )?(.+?): (.*) in (.+):([0-9]+)$`)
+	phpStacktraceFrameRegexp    = regexp.MustCompile(`^#[0-9]+ (?:(.+)\(([0-9]+)\)|\[internal function\]): (.*)$`)
+	goStacktraceGoroutineRegexp = regexp.MustCompile(`^goroutine [0-9]+ \[.*\]:$`)
+	goStacktraceLocRegexp       = regexp.MustCompile(`^\t(.*):([0-9]+)(?: \+0x[0-9a-f]+)?$`)
+)
+
+// setPythonExceptionStacktrace parses a Python traceback, as formatted by
+// the traceback module. Chained exceptions are printed before the exception
+// they caused, and are recorded as causes.
+func setPythonExceptionStacktrace(s string, out *modelpb.Exception) error {
+	const (
+		tracebackHeader = "Traceback (most recent call last):"
+		repeatedPrefix  = "  [Previous line repeated "
+	)
+
+	// chain holds the exceptions in the order they are printed;
+	// the last one is the exception the traceback was printed for.
+	var chain []*modelpb.Exception
+	var current *modelpb.Exception
+	var frame *modelpb.StacktraceFrame
+	var haveMessage bool
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == tracebackHeader:
+			current = &modelpb.Exception{}
+			chain = append(chain, current)
+			frame, haveMessage = nil, false
+		case strings.HasPrefix(line, "  File "):
+			if current == nil || haveMessage {
+				return fmt.Errorf("no traceback preceding line %q", line)
+			}
+			submatch := pythonStacktraceFileRegexp.FindStringSubmatch(line)
+			if submatch == nil {
+				return fmt.Errorf("failed to parse stacktrace line %q", line)
+			}
+			frame = &modelpb.StacktraceFrame{
+				Filename: submatch[1],
+				Lineno:   parseStacktraceLineno(submatch[2]),
+				Function: submatch[3],
+			}
+			current.Stacktrace = append(current.Stacktrace, frame)
+		case strings.HasPrefix(line, "  |"), strings.HasPrefix(line, "  +"):
+			return errors.New("parsing exception groups not implemented")
+		case strings.HasPrefix(line, repeatedPrefix):
+			// Repeated frames are elided by the traceback module.
+		case strings.HasPrefix(line, "    "):
+			// Source code for the preceding frame, possibly followed
+			// by lines pointing at the failing expression.
+			if frame != nil && frame.ContextLine == "" {
+				frame.ContextLine = strings.TrimSpace(line)
+			}
+		case line == "",
+			line == "During handling of the above exception, another exception occurred:",
+			line == "The above exception was the direct cause of the following exception:":
+			if haveMessage {
+				current, frame, haveMessage = nil, nil, false
+			}
+		default:
+			if current == nil {
+				// Exceptions which were never raised have no traceback.
+				current = &modelpb.Exception{}
+				chain = append(chain, current)
+			}
+			if haveMessage {
+				// Multi-line exception message.
+				current.Message += "\n" + line
+				break
+			}
+			current.Type, current.Message = splitExceptionTypeMessage(line)
+			frame, haveMessage = nil, true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(chain) == 0 || len(chain[len(chain)-1].Stacktrace) == 0 {
+		return errors.New("no traceback found")
+	}
+	// Python prints the most recent call last.
+	for _, e := range chain {
+		reverseStacktrace(e.Stacktrace)
+	}
+	out.Stacktrace = chain[len(chain)-1].Stacktrace
+	setExceptionCauseChain(out, chain[:len(chain)-1])
+	return nil
+}
+
+// setNodeExceptionStacktrace parses a V8 stack trace, as found in the
+// `stack` property of JavaScript errors.
+func setNodeExceptionStacktrace(s string, out *modelpb.Exception) error {
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for scanner.Scan() {
+		line := strings.TrimLeft(scanner.Text(), " \t")
+		if !strings.HasPrefix(line, "at ") {
+			if len(out.Stacktrace) == 0 || line == "" {
+				// The error message may span multiple lines.
+				continue
+			}
+			return fmt.Errorf("unexpected line %q", line)
+		}
+		// Lines are of the form "at [async ][function ](location)",
+		// or "at location" if the function is anonymous.
+		line = strings.TrimPrefix(line[len("at "):], "async ")
+		var function string
+		location := line
+		if i := strings.Index(line, " ("); i >= 0 && strings.HasSuffix(line, ")") {
+			function, location = line[:i], line[i+2:len(line)-1]
+		}
+		frame := &modelpb.StacktraceFrame{Function: function, Filename: location}
+		if submatch := nodeStacktraceLocRegexp.FindStringSubmatch(location); submatch != nil {
+			frame.Filename = submatch[1]
+			frame.Lineno = parseStacktraceLineno(submatch[2])
+			frame.Colno = parseStacktraceLineno(submatch[3])
+		}
+		out.Stacktrace = append(out.Stacktrace, frame)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(out.Stacktrace) == 0 {
+		return errors.New("no stack frames found")
+	}
+	return nil
+}
+
+// setDotnetExceptionStacktrace parses a .NET stack trace, as formatted by
+// Exception.ToString. Inner exceptions are recorded as causes.
+func setDotnetExceptionStacktrace(s string, out *modelpb.Exception) error {
+	const (
+		innerPrefix    = "---> "
+		endOfInner     = "--- End of inner exception stack trace ---"
+		endOfLocation  = "--- End of stack trace from previous location"
+		innerSeparator = " ---> "
+	)
+
+	// Inner exceptions are printed before the frames of the
+	// exceptions enclosing them, so we descend into them while
+	// reading the message lines and ascend at the end of each
+	// inner exception stack trace.
+	current := out
+	var stack []*modelpb.Exception
+	descend := func(typeAndMessage string) {
+		cause := &modelpb.Exception{Handled: out.Handled}
+		cause.Type, cause.Message = splitExceptionTypeMessage(typeAndMessage)
+		current.Cause = []*modelpb.Exception{cause}
+		stack = append(stack, current)
+		current = cause
+	}
+
+	var haveFrames bool
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "at "):
+			submatch := dotnetStacktraceAtRegexp.FindStringSubmatch(line)
+			if submatch == nil {
+				return fmt.Errorf("failed to parse stacktrace line %q", line)
+			}
+			classname, function := splitDotnetMethod(submatch[1])
+			current.Stacktrace = append(current.Stacktrace, &modelpb.StacktraceFrame{
+				Classname: classname,
+				Function:  function,
+				Filename:  submatch[2],
+				Lineno:    parseStacktraceLineno(submatch[3]),
+			})
+			haveFrames = true
+		case line == endOfInner:
+			if len(stack) == 0 {
+				return fmt.Errorf("no inner exception preceding line %q", line)
+			}
+			n := len(stack)
+			current, stack = stack[n-1], stack[:n-1]
+		case strings.HasPrefix(line, endOfLocation):
+			// Async methods resume at a different location,
+			// but the frames belong to the same exception.
+		case !haveFrames:
+			// Message lines, possibly introducing inner exceptions
+			// either on separate lines or on the same line.
+			if strings.HasPrefix(line, innerPrefix) {
+				line = " " + line
+			}
+			parts := strings.Split(line, innerSeparator)
+			for _, part := range parts[1:] {
+				descend(part)
+			}
+		case line == "":
+		default:
+			return fmt.Errorf("unexpected line %q", line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if !haveFrames {
+		return errors.New("no stack frames found")
+	}
+	return nil
+}
+
+// splitDotnetMethod splits a .NET method, e.g. "Ns.Type.Method(String s)",
+// into its class name and method name.
+func splitDotnetMethod(s string) (classname, function string) {
+	if i := strings.IndexByte(s, '('); i >= 0 {
+		s = s[:i]
+	}
+	i := strings.LastIndexByte(s, '.')
+	if i <= 0 {
+		return "", s
+	}
+	if s[i-1] == '.' {
+		// Constructors, e.g. "Ns.Type..ctor".
+		i--
+	}
+	return s[:i], s[i+1:]
+}
+
+// setRubyExceptionStacktrace parses a Ruby stack trace, as formatted by
+// Exception#full_message with order: :top. Causes are printed after the
+// exception they caused.
+func setRubyExceptionStacktrace(s string, out *modelpb.Exception) error {
+	current := out
+	first := true
+	var haveFrom bool
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if submatch := rubyStacktraceFromRegexp.FindStringSubmatch(line); submatch != nil {
+			if first {
+				return fmt.Errorf("no exception preceding line %q", line)
+			}
+			current.Stacktrace = append(current.Stacktrace, &modelpb.StacktraceFrame{
+				Filename: submatch[1],
+				Lineno:   parseStacktraceLineno(submatch[2]),
+				Function: submatch[3],
+			})
+			haveFrom = true
+			continue
+		}
+		if strings.HasPrefix(line, "\t ... ") {
+			// Frames common with the enclosing exception are elided.
+			continue
+		}
+		submatch := rubyStacktraceHeaderRegexp.FindStringSubmatch(line)
+		if submatch == nil {
+			if first || haveFrom {
+				return fmt.Errorf("unexpected line %q", line)
+			}
+			// The exception message may span multiple lines.
+			continue
+		}
+		if !first {
+			cause := &modelpb.Exception{
+				Type:    submatch[5],
+				Message: submatch[4],
+				Handled: out.Handled,
+			}
+			current.Cause = []*modelpb.Exception{cause}
+			current = cause
+		}
+		current.Stacktrace = append(current.Stacktrace, &modelpb.StacktraceFrame{
+			Filename: submatch[1],
+			Lineno:   parseStacktraceLineno(submatch[2]),
+			Function: submatch[3],
+		})
+		first, haveFrom = false, false
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if first {
+		return errors.New("no stack frames found")
+	}
+	return nil
+}
+
+// setPHPExceptionStacktrace parses a PHP stack trace, as formatted by
+// Throwable::__toString. Previous exceptions are printed before the
+// exception they caused, which is introduced with "Next".
+func setPHPExceptionStacktrace(s string, out *modelpb.Exception) error {
+	type phpException struct {
+		typ, message string
+		file         string
+		line         *uint32
+		frames       []*modelpb.StacktraceFrame
+	}
+	var chain []*phpException
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "", line == "Stack trace:":
+		case strings.HasPrefix(line, "#"):
+			if len(chain) == 0 {
+				return fmt.Errorf("no exception preceding line %q", line)
+			}
+			current := chain[len(chain)-1]
+			if strings.HasSuffix(line, " {main}") {
+				current.frames = append(current.frames, &modelpb.StacktraceFrame{})
+				break
+			}
+			submatch := phpStacktraceFrameRegexp.FindStringSubmatch(line)
+			if submatch == nil {
+				return fmt.Errorf("failed to parse stacktrace line %q", line)
+			}
+			classname, function := splitPHPFunction(submatch[3])
+			current.frames = append(current.frames, &modelpb.StacktraceFrame{
+				Classname: classname,
+				Function:  function,
+				Filename:  submatch[1],
+				Lineno:    parseStacktraceLineno(submatch[2]),
+			})
+		default:
+			submatch := phpStacktraceHeaderRegexp.FindStringSubmatch(line)
+			if submatch == nil {
+				return fmt.Errorf("unexpected line %q", line)
+			}
+			chain = append(chain, &phpException{
+				typ:     submatch[1],
+				message: submatch[2],
+				file:    submatch[3],
+				line:    parseStacktraceLineno(submatch[4]),
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(chain) == 0 {
+		return errors.New("no exception found")
+	}
+
+	// Each trace entry records where a function was called from, and
+	// the exception records where it was thrown. Shift the locations
+	// so that each frame holds a function and the location within it.
+	exceptions := make([]*modelpb.Exception, len(chain))
+	for i, e := range chain {
+		exception := &modelpb.Exception{Type: e.typ, Message: e.message, Handled: out.Handled}
+		file, lineno := e.file, e.line
+		for _, entry := range e.frames {
+			exception.Stacktrace = append(exception.Stacktrace, &modelpb.StacktraceFrame{
+				Classname: entry.Classname,
+				Function:  entry.Function,
+				Filename:  file,
+				Lineno:    lineno,
+			})
+			file, lineno = entry.Filename, entry.Lineno
+		}
+		exceptions[i] = exception
+	}
+	out.Stacktrace = exceptions[len(exceptions)-1].Stacktrace
+	setExceptionCauseChain(out, exceptions[:len(exceptions)-1])
+	return nil
+}
+
+// splitPHPFunction splits a PHP function call, e.g. "App\Foo->bar('x')",
+// into its class name and function name.
+func splitPHPFunction(s string) (classname, function string) {
+	if i := strings.IndexByte(s, '('); i >= 0 {
+		s = s[:i]
+	}
+	for _, sep := range []string{"->", "::"} {
+		if i := strings.Index(s, sep); i >= 0 {
+			return s[:i], s[i+len(sep):]
+		}
+	}
+	return "", s
+}
+
+// setGoExceptionStacktrace parses a Go goroutine stack dump, as printed
+// for unrecovered panics or by runtime/debug.Stack. Only the first
+// goroutine, which is the one that panicked, is parsed.
+func setGoExceptionStacktrace(s string, out *modelpb.Exception) error {
+	var inGoroutine bool
+	var frame *modelpb.StacktraceFrame
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !inGoroutine {
+			// Skip panic messages and signal information.
+			inGoroutine = goStacktraceGoroutineRegexp.MatchString(line)
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "created by ") {
+			// End of the goroutine's stack.
+			break
+		}
+		if line == "...additional frames elided..." {
+			continue
+		}
+		if strings.HasPrefix(line, "\t") {
+			submatch := goStacktraceLocRegexp.FindStringSubmatch(line)
+			if submatch == nil || frame == nil {
+				return fmt.Errorf("failed to parse stacktrace line %q", line)
+			}
+			frame.Filename = submatch[1]
+			frame.Lineno = parseStacktraceLineno(submatch[2])
+			frame = nil
+			continue
+		}
+		// Function lines are of the form "pkg/path.Func(args)".
+		if i := strings.LastIndexByte(line, '('); i > 0 && strings.HasSuffix(line, ")") {
+			line = line[:i]
+		}
+		var module string
+		function := line
+		slash := strings.LastIndexByte(line, '/')
+		if dot := strings.IndexByte(line[slash+1:], '.'); dot >= 0 {
+			module, function = line[:slash+1+dot], line[slash+1+dot+1:]
+		}
+		frame = &modelpb.StacktraceFrame{Module: module, Function: function}
+		out.Stacktrace = append(out.Stacktrace, frame)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(out.Stacktrace) == 0 {
+		return errors.New("no goroutine stack found")
+	}
+	return nil
+}
+
+// setExceptionCauseChain records causes as a chain of causes of out,
+// where the last element of causes is the direct cause of out.
+func setExceptionCauseChain(out *modelpb.Exception, causes []*modelpb.Exception) {
+	current := out
+	for i := len(causes) - 1; i >= 0; i-- {
+		cause := causes[i]
+		cause.Handled = out.Handled
+		current.Cause = []*modelpb.Exception{cause}
+		current = cause
+	}
+}
+
+// splitExceptionTypeMessage splits "Type: message" into its parts.
+func splitExceptionTypeMessage(s string) (string, string) {
+	if i := strings.Index(s, ": "); i >= 0 {
+		return s[:i], s[i+2:]
+	}
+	return s, ""
+}
+
+func reverseStacktrace(frames []*modelpb.StacktraceFrame) {
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+}
+
+func parseStacktraceLineno(s string) *uint32 {
+	if s == "" {
+		return nil
+	}
+	n, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return nil
+	}
+	u := uint32(n)
+	return &u
+}
@@ -0,0 +1,210 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package otlp
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/elastic/apm-data/model/modelpb"
+)
+
+// defaultSumStateTTL is the default for ConsumerConfig.SumStateTTL.
+const defaultSumStateTTL = 10 * time.Minute
+
+func aggregationTemporality(t pmetric.AggregationTemporality) modelpb.AggregationTemporality {
+	switch t {
+	case pmetric.AggregationTemporalityDelta:
+		return modelpb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA
+	case pmetric.AggregationTemporalityCumulative:
+		return modelpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+	}
+	return modelpb.AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED
+}
+
+// attributesSignature returns a string uniquely identifying the given
+// attributes, independent of their order.
+func attributesSignature(attributes pcommon.Map) string {
+	if attributes.Len() == 0 {
+		return ""
+	}
+	keys := make([]string, 0, attributes.Len())
+	attributes.Range(func(k string, _ pcommon.Value) bool {
+		keys = append(keys, k)
+		return true
+	})
+	sort.Strings(keys)
+	var sb strings.Builder
+	for _, k := range keys {
+		v, _ := attributes.Get(k)
+		sb.WriteString(k)
+		sb.WriteByte(0)
+		sb.WriteString(v.AsString())
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
+
+// sumKey identifies a sum metric time series.
+type sumKey struct {
+	resource     string
+	scope        string
+	scopeVersion string
+	name         string
+	attributes   string
+}
+
+type sumState struct {
+	start    pcommon.Timestamp
+	last     pcommon.Timestamp
+	value    float64
+	lastSeen time.Time
+}
+
+// sumConversion describes the outcome of converting a sum data point.
+type sumConversion int
+
+const (
+	// sumConverted means the data point was converted, and should be
+	// recorded.
+	sumConverted sumConversion = iota
+
+	// sumSeeded means the data point was the first seen for a cumulative
+	// series, and was used as the base for calculating the following
+	// deltas. It should be dropped.
+	sumSeeded
+
+	// sumOutOfOrder means the data point was not newer than the previous
+	// data point for the series, and should be dropped.
+	sumOutOfOrder
+)
+
+// sumConverter converts sum data points between delta and cumulative
+// aggregation temporality, tracking the state of each time series.
+type sumConverter struct {
+	temporality modelpb.AggregationTemporality
+	ttl         time.Duration
+	start       pcommon.Timestamp
+
+	mu        sync.Mutex
+	states    map[sumKey]*sumState
+	lastPrune time.Time
+}
+
+func newSumConverter(temporality modelpb.AggregationTemporality, ttl time.Duration) *sumConverter {
+	if ttl <= 0 {
+		ttl = defaultSumStateTTL
+	}
+	return &sumConverter{
+		temporality: temporality,
+		ttl:         ttl,
+		start:       pcommon.NewTimestampFromTime(time.Now()),
+		states:      make(map[sumKey]*sumState),
+		lastPrune:   time.Now(),
+	}
+}
+
+// convert converts sample, created from dp, to the converter's aggregation
+// temporality. convert returns sumConverted if the data point should be
+// recorded, or otherwise the reason for dropping it.
+// Non-monotonic sums are not converted: they are recorded as gauges, and
+// their deltas would not be meaningful as such.
+//
+// Converting cumulative sums to deltas requires a previous data point
+// for the series. When the start timestamp changes, the series has been
+// reset and the value is the delta since the start timestamp; decreasing
+// sums are also treated as resets. The first data point seen for a series
+// is only recorded if it has a start timestamp after the converter was
+// created: otherwise its value may include increments which were already
+// recorded, e.g. before the consumer was restarted or the series' state
+// expired. Data points which are not newer than the previous data point
+// are dropped.
+//
+// Converting deltas to cumulative sums accumulates the deltas for each
+// series. When a data point's start timestamp precedes the previous data
+// point's timestamp, the series is assumed to have been restarted, and
+// accumulation restarts.
+func (c *sumConverter) convert(key sumKey, dp pmetric.NumberDataPoint, monotonic bool, sample *modelpb.MetricsetSample) sumConversion {
+	if !monotonic ||
+		sample.Temporality == modelpb.AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED ||
+		sample.Temporality == c.temporality {
+		return sumConverted
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.prune(now)
+
+	start, timestamp := dp.StartTimestamp(), dp.Timestamp()
+	state, ok := c.states[key]
+	if !ok {
+		state = &sumState{}
+		c.states[key] = state
+	}
+	switch c.temporality {
+	case modelpb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA:
+		value := sample.Value
+		switch {
+		case !ok || start != state.start:
+			if start == 0 || (!ok && start <= c.start) {
+				// No previous value or start timestamp from which
+				// a delta can be calculated.
+				*state = sumState{start: start, last: timestamp, value: value, lastSeen: now}
+				return sumSeeded
+			}
+		case timestamp <= state.last:
+			return sumOutOfOrder
+		case value >= state.value:
+			sample.Value = value - state.value
+		}
+		*state = sumState{start: start, last: timestamp, value: value, lastSeen: now}
+	case modelpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE:
+		if !ok || (start != 0 && start < state.last) {
+			if start == 0 {
+				start = timestamp
+			}
+			*state = sumState{start: start}
+		}
+		state.value += sample.Value
+		state.last = timestamp
+		state.lastSeen = now
+		sample.Value = state.value
+	}
+	sample.Temporality = c.temporality
+	return sumConverted
+}
+
+// prune removes the state of series which have not been seen within
+// the TTL. prune must be called with c.mu held.
+func (c *sumConverter) prune(now time.Time) {
+	if now.Sub(c.lastPrune) < c.ttl {
+		return
+	}
+	for key, state := range c.states {
+		if now.Sub(state.lastSeen) >= c.ttl {
+			delete(c.states, key)
+		}
+	}
+	c.lastPrune = now
+}
@@ -42,6 +42,7 @@ import (
 	"net"
 	"net/netip"
 	"net/url"
+	"path"
 	"strconv"
 	"strings"
 	"time"
@@ -73,31 +74,110 @@ const (
 	attributeNetworkMNC               = "net.host.carrier.mnc"
 	attributeNetworkCarrierName       = "net.host.carrier.name"
 	attributeNetworkICC               = "net.host.carrier.icc"
+
+	// Attributes defined by the stable HTTP and networking semantic
+	// conventions, which supersede those in semconv/v1.5.0.
+	attributeHTTPRequestMethod         = "http.request.method"
+	attributeHTTPRequestMethodOriginal = "http.request.method_original"
+	attributeHTTPResponseStatusCode    = "http.response.status_code"
+	attributeURLFull                   = "url.full"
+	attributeURLPath                   = "url.path"
+	attributeURLQuery                  = "url.query"
+	attributeURLScheme                 = "url.scheme"
+	attributeServerAddress             = "server.address"
+	attributeServerPort                = "server.port"
+	attributeClientAddress             = "client.address"
+	attributeClientPort                = "client.port"
+	attributeNetworkPeerAddress        = "network.peer.address"
+	attributeNetworkPeerPort           = "network.peer.port"
+	attributeNetworkProtocolVersion    = "network.protocol.version"
+	attributeUserAgentOriginal         = "user_agent.original"
+
+	// Attributes defined by the messaging semantic conventions v1.17+.
+	attributeMessagingDestinationName      = "messaging.destination.name"
+	attributeMessagingDestinationTemporary = "messaging.destination.temporary"
+	attributeMessagingSourceName           = "messaging.source.name"
+	attributeMessagingSourceTemporary      = "messaging.source.temporary"
+	attributeMessagingBatchMessageCount    = "messaging.batch.message_count"
+	attributeMessagingMessageID            = "messaging.message.id"
+	attributeMessagingConversationID       = "messaging.message.conversation_id"
+	attributeMessagingKafkaPartition       = "messaging.kafka.destination.partition"
+	attributeMessagingKafkaConsumerGroup   = "messaging.kafka.consumer.group"
+	attributeMessagingKafkaMessageKey      = "messaging.kafka.message.key"
+	attributeMessagingRabbitMQRoutingKey   = "messaging.rabbitmq.destination.routing_key"
+	attributeAWSSQSQueueURL                = "aws.sqs.queue.url"
+
+	// attributeKafkaRecordQueueTimeMs is recorded on Kafka consumer spans
+	// by the OpenTelemetry Java instrumentation, when experimental span
+	// attributes are enabled. It holds the time in milliseconds between
+	// the record being produced and consumed, i.e. the message age. The
+	// messaging semantic conventions have no equivalent attribute, so
+	// the age is otherwise not recorded.
+	attributeKafkaRecordQueueTimeMs = "kafka.record.queue_time_ms"
+
+	// Attributes defined by the database semantic conventions v1.26+.
+	attributeDBSystemName         = "db.system.name"
+	attributeDBQueryText          = "db.query.text"
+	attributeDBNamespace          = "db.namespace"
+	attributeDBCollectionName     = "db.collection.name"
+	attributeDBOperationName      = "db.operation.name"
+	attributeDBResponseStatusCode = "db.response.status_code"
+
+	// Attributes defined by the generative AI semantic conventions.
+	attributeGenAISystem                = "gen_ai.system"
+	attributeGenAIProviderName          = "gen_ai.provider.name"
+	attributeGenAIOperationName         = "gen_ai.operation.name"
+	attributeGenAIRequestModel          = "gen_ai.request.model"
+	attributeGenAIResponseModel         = "gen_ai.response.model"
+	attributeGenAIUsageInputTokens      = "gen_ai.usage.input_tokens"
+	attributeGenAIUsageOutputTokens     = "gen_ai.usage.output_tokens"
+	attributeGenAIUsagePromptTokens     = "gen_ai.usage.prompt_tokens"
+	attributeGenAIUsageCompletionTokens = "gen_ai.usage.completion_tokens"
+
+	// FaaS attributes introduced in semconv v1.17.0.
+	attributeFaaSInvocationID = "faas.invocation_id"
 )
 
 // ConsumeTraces consumes OpenTelemetry trace data,
 // converting into Elastic APM events and reporting to the Elastic APM schema.
+//
+// ConsumeTraces implements consumer.Traces; use ConsumeTracesWithResult
+// to learn which spans were rejected.
 func (c *Consumer) ConsumeTraces(ctx context.Context, traces ptrace.Traces) error {
-	if err := c.sem.Acquire(ctx, 1); err != nil {
-		return err
+	_, err := c.ConsumeTracesWithResult(ctx, traces)
+	return err
+}
+
+// ConsumeTracesWithResult consumes OpenTelemetry trace data, converting into
+// Elastic APM events and reporting to the Elastic APM schema. The result
+// records the number of spans accepted and rejected.
+//
+// If processing fails, the error is returned along with a result counting
+// the spans in batches processed before the failure.
+func (c *Consumer) ConsumeTracesWithResult(ctx context.Context, traces ptrace.Traces) (ConsumeTracesResult, error) {
+	if err := c.semAcquire(ctx); err != nil {
+		return ConsumeTracesResult{}, err
 	}
-	defer c.sem.Release(1)
+	out := c.newBatchSplitter(ctx)
+	defer out.release()
 
 	receiveTimestamp := time.Now()
 	c.config.Logger.Debug("consuming traces", zap.Stringer("traces", tracesStringer(traces)))
 
 	resourceSpans := traces.ResourceSpans()
-	batch := make(modelpb.Batch, 0, resourceSpans.Len())
-	for i := 0; i < resourceSpans.Len(); i++ {
-		c.convertResourceSpans(resourceSpans.At(i), receiveTimestamp, &batch)
+	for i := 0; i < resourceSpans.Len() && out.err == nil; i++ {
+		c.convertResourceSpans(resourceSpans.At(i), receiveTimestamp, out)
 	}
-	return c.config.Processor.ProcessBatch(ctx, &batch)
+	err := out.close()
+	r := ConsumeTracesResult{AcceptedSpans: out.accepted}
+	c.stats.add(&c.stats.acceptedSpans, &c.stats.rejectedSpans, r.AcceptedSpans, r.RejectedSpans)
+	return r, err
 }
 
 func (c *Consumer) convertResourceSpans(
 	resourceSpans ptrace.ResourceSpans,
 	receiveTimestamp time.Time,
-	out *modelpb.Batch,
+	out *batchSplitter,
 ) {
 	baseEvent := modelpb.APMEvent{
 		Event: &modelpb.Event{
@@ -111,8 +191,9 @@ func (c *Consumer) convertResourceSpans(
 		timeDelta = receiveTimestamp.Sub(exportTimestamp)
 	}
 	scopeSpans := resourceSpans.ScopeSpans()
-	for i := 0; i < scopeSpans.Len(); i++ {
+	for i := 0; i < scopeSpans.Len() && out.err == nil; i++ {
 		c.convertScopeSpans(scopeSpans.At(i), &baseEvent, timeDelta, out)
+		out.boundary()
 	}
 }
 
@@ -120,11 +201,12 @@ func (c *Consumer) convertScopeSpans(
 	in ptrace.ScopeSpans,
 	baseEvent *modelpb.APMEvent,
 	timeDelta time.Duration,
-	out *modelpb.Batch,
+	out *batchSplitter,
 ) {
 	otelSpans := in.Spans()
 	for i := 0; i < otelSpans.Len(); i++ {
-		c.convertSpan(otelSpans.At(i), in.Scope(), baseEvent, timeDelta, out)
+		c.convertSpan(otelSpans.At(i), in.Scope(), baseEvent, timeDelta, &out.batch)
+		out.mark(1)
 	}
 }
 
@@ -221,17 +303,22 @@ func TranslateTransaction(
 	)
 
 	var (
-		httpScheme     string
-		httpURL        string
-		httpServerName string
-		httpHost       string
-		http           modelpb.HTTP
-		httpRequest    modelpb.HTTPRequest
-		httpResponse   modelpb.HTTPResponse
+		httpScheme         string
+		httpURL            string
+		httpServerName     string
+		httpHost           string
+		httpMethodOriginal string
+		urlPath            string
+		urlQuery           string
+		http               modelpb.HTTP
+		httpRequest        modelpb.HTTPRequest
+		httpResponse       modelpb.HTTPResponse
 	)
 
-	var isHTTP, isRPC, isMessaging bool
+	var isHTTP, isRPC, isMessaging, isFaaS bool
 	var message modelpb.Message
+	var messageQueue messageQueue
+	var faas modelpb.Faas
 
 	var samplerType, samplerParam pcommon.Value
 	attributes.Range(func(kDots string, v pcommon.Value) bool {
@@ -251,23 +338,41 @@ func TranslateTransaction(
 		case pcommon.ValueTypeSlice:
 			setLabel(k, event, ifaceAttributeValue(v))
 		case pcommon.ValueTypeBool:
-			setLabel(k, event, ifaceAttributeValue(v))
+			switch kDots {
+			case semconv.AttributeFaaSColdstart:
+				isFaaS = true
+				coldStart := v.Bool()
+				faas.ColdStart = &coldStart
+			default:
+				setLabel(k, event, ifaceAttributeValue(v))
+			}
 		case pcommon.ValueTypeDouble:
 			setLabel(k, event, ifaceAttributeValue(v))
 		case pcommon.ValueTypeInt:
 			switch kDots {
-			case semconv.AttributeHTTPStatusCode:
+			case semconv.AttributeHTTPStatusCode, attributeHTTPResponseStatusCode:
 				isHTTP = true
 				httpResponse.StatusCode = int32(v.Int())
 				http.Response = &httpResponse
-			case semconv.AttributeNetPeerPort:
+			case semconv.AttributeNetPeerPort, attributeNetworkPeerPort:
 				event.Source = populateNil(event.Source)
 				event.Source.Port = uint32(v.Int())
-			case semconv.AttributeNetHostPort:
+			case semconv.AttributeNetHostPort, attributeServerPort:
 				netHostPort = int(v.Int())
+			case attributeClientPort:
+				event.Client = populateNil(event.Client)
+				event.Client.Port = uint32(v.Int())
 			case semconv.AttributeRPCGRPCStatusCode:
 				isRPC = true
 				event.Transaction.Result = codes.Code(v.Int()).String()
+			case attributeMessagingBatchMessageCount, semconv.AttributeMessagingKafkaPartition, attributeMessagingKafkaPartition:
+				// Recorded as labels, as there are no equivalent fields.
+				isMessaging = true
+				setLabel(k, event, ifaceAttributeValue(v))
+			case attributeKafkaRecordQueueTimeMs:
+				age := v.Int()
+				message.AgeMillis = &age
+				isMessaging = true
 			default:
 				setLabel(k, event, ifaceAttributeValue(v))
 			}
@@ -276,17 +381,19 @@ func TranslateTransaction(
 			stringval := truncate(v.Str())
 			switch kDots {
 			// http.*
-			case semconv.AttributeHTTPMethod:
+			case semconv.AttributeHTTPMethod, attributeHTTPRequestMethod:
 				isHTTP = true
 				httpRequest.Method = stringval
 				http.Request = &httpRequest
-			case semconv.AttributeHTTPURL, semconv.AttributeHTTPTarget, "http.path":
+			case attributeHTTPRequestMethodOriginal:
+				httpMethodOriginal = stringval
+			case semconv.AttributeHTTPURL, semconv.AttributeHTTPTarget, "http.path", attributeURLFull:
 				isHTTP = true
 				httpURL = stringval
 			case semconv.AttributeHTTPHost:
 				isHTTP = true
 				httpHost = stringval
-			case semconv.AttributeHTTPScheme:
+			case semconv.AttributeHTTPScheme, attributeURLScheme:
 				isHTTP = true
 				httpScheme = stringval
 			case semconv.AttributeHTTPStatusCode:
@@ -306,20 +413,29 @@ func TranslateTransaction(
 			case semconv.AttributeHTTPFlavor:
 				isHTTP = true
 				http.Version = stringval
+			case attributeNetworkProtocolVersion:
+				http.Version = stringval
 			case semconv.AttributeHTTPServerName:
 				isHTTP = true
 				httpServerName = stringval
-			case semconv.AttributeHTTPClientIP:
+			case semconv.AttributeHTTPClientIP, attributeClientAddress:
 				if ip, err := netip.ParseAddr(stringval); err == nil {
 					event.Client = populateNil(event.Client)
 					event.Client.Ip = ip.String()
 				}
-			case semconv.AttributeHTTPUserAgent:
+			case semconv.AttributeHTTPUserAgent, attributeUserAgentOriginal:
 				event.UserAgent = populateNil(event.UserAgent)
 				event.UserAgent.Original = stringval
 
+			// url.*
+			case attributeURLPath:
+				isHTTP = true
+				urlPath = stringval
+			case attributeURLQuery:
+				urlQuery = stringval
+
 			// net.*
-			case semconv.AttributeNetPeerIP:
+			case semconv.AttributeNetPeerIP, attributeNetworkPeerAddress:
 				event.Source = populateNil(event.Source)
 				if ip, err := netip.ParseAddr(stringval); err == nil {
 					event.Source.Ip = ip.String()
@@ -327,7 +443,7 @@ func TranslateTransaction(
 			case semconv.AttributeNetPeerName:
 				event.Source = populateNil(event.Source)
 				event.Source.Domain = stringval
-			case semconv.AttributeNetHostName:
+			case semconv.AttributeNetHostName, attributeServerAddress:
 				netHostName = stringval
 			case attributeNetworkConnectionType:
 				event.Network = populateNil(event.Network)
@@ -355,8 +471,34 @@ func TranslateTransaction(
 				event.Network.Carrier.Icc = stringval
 
 			// messaging.*
-			case "message_bus.destination", semconv.AttributeMessagingDestination:
-				message.QueueName = stringval
+			//
+			// modelpb.Message only has fields for the queue name, routing
+			// key, and age (see attributeKafkaRecordQueueTimeMs). Message
+			// and conversation IDs, Kafka consumer groups, message keys and
+			// partitions, and batch sizes have no equivalent fields in the
+			// Elastic APM data model, so they are deliberately recorded as
+			// labels, which keeps them searchable.
+			case "message_bus.destination", semconv.AttributeMessagingDestination, attributeMessagingDestinationName:
+				messageQueue.destination = stringval
+				isMessaging = true
+			case attributeMessagingSourceName:
+				messageQueue.source = stringval
+				isMessaging = true
+			case semconv.AttributeMessagingURL, attributeAWSSQSQueueURL:
+				messageQueue.url = stringval
+				modelpb.Labels(event.Labels).Set(k, stringval)
+			case semconv.AttributeMessagingSystem:
+				messageQueue.system = stringval
+				modelpb.Labels(event.Labels).Set(k, stringval)
+			case semconv.AttributeMessagingRabbitmqRoutingKey, attributeMessagingRabbitMQRoutingKey:
+				message.RoutingKey = stringval
+				isMessaging = true
+			case semconv.AttributeMessagingMessageID, attributeMessagingMessageID,
+				semconv.AttributeMessagingConversationID, attributeMessagingConversationID,
+				semconv.AttributeMessagingKafkaConsumerGroup, attributeMessagingKafkaConsumerGroup,
+				semconv.AttributeMessagingKafkaMessageKey, attributeMessagingKafkaMessageKey:
+				// Recorded as labels, as there are no equivalent fields.
+				modelpb.Labels(event.Labels).Set(k, stringval)
 				isMessaging = true
 
 			// rpc.*
@@ -371,6 +513,14 @@ func TranslateTransaction(
 			case semconv.AttributeRPCService:
 			case semconv.AttributeRPCMethod:
 
+			// faas.*
+			case semconv.AttributeFaaSTrigger:
+				isFaaS = true
+				faas.TriggerType = stringval
+			case semconv.AttributeFaaSExecution, attributeFaaSInvocationID:
+				isFaaS = true
+				faas.Execution = stringval
+
 			// miscellaneous
 			case "type":
 				event.Transaction.Type = stringval
@@ -402,6 +552,9 @@ func TranslateTransaction(
 	}
 
 	if isHTTP {
+		if httpRequest.Method == "_OTHER" && httpMethodOriginal != "" {
+			httpRequest.Method = httpMethodOriginal
+		}
 		if !proto.Equal(&http, &modelpb.HTTP{}) {
 			event.Http = &http
 		}
@@ -416,7 +569,13 @@ func TranslateTransaction(
 			}
 		}
 
-		// Build the modelpb.URL from http{URL,Host,Scheme}.
+		// Build the modelpb.URL from http{URL,Host,Scheme}, or from url.*.
+		if httpURL == "" && urlPath != "" {
+			httpURL = urlPath
+			if urlQuery != "" {
+				httpURL += "?" + urlQuery
+			}
+		}
 		httpHost := httpHost
 		if httpHost == "" {
 			httpHost = httpServerName
@@ -433,8 +592,17 @@ func TranslateTransaction(
 		event.Url = modelpb.ParseURL(httpURL, httpHost, httpScheme)
 	}
 	if isMessaging {
+		message.QueueName = messageQueue.name()
 		event.Transaction.Message = &message
 	}
+	if isFaaS {
+		// Function details are recorded in resource attributes,
+		// and the invocation details in transaction attributes.
+		event.Faas = populateNil(event.Faas)
+		event.Faas.TriggerType = faas.TriggerType
+		event.Faas.Execution = faas.Execution
+		event.Faas.ColdStart = faas.ColdStart
+	}
 
 	if event.Source != nil {
 		if _, err := netip.ParseAddr(event.GetClient().GetIp()); err != nil {
@@ -465,9 +633,10 @@ func TranslateSpan(spanKind ptrace.SpanKind, attributes pcommon.Map, event *mode
 	isJaeger := strings.HasPrefix(event.GetAgent().GetName(), "Jaeger")
 
 	var (
-		netPeerName string
-		netPeerIP   string
-		netPeerPort int
+		netPeerName     string
+		netPeerIP       string
+		netPeerPort     int
+		networkPeerPort int
 	)
 
 	var (
@@ -476,14 +645,17 @@ func TranslateSpan(spanKind ptrace.SpanKind, attributes pcommon.Map, event *mode
 	)
 
 	var (
-		httpURL    string
-		httpHost   string
-		httpTarget string
-		httpScheme = "http"
+		httpURL            string
+		httpHost           string
+		httpTarget         string
+		httpScheme         = "http"
+		httpMethodOriginal string
+		urlPath            string
+		urlQuery           string
 	)
 
 	var (
-		messageSystem          string
+		messageQueue           messageQueue
 		messageOperation       string
 		messageTempDestination bool
 	)
@@ -493,14 +665,22 @@ func TranslateSpan(spanKind ptrace.SpanKind, attributes pcommon.Map, event *mode
 		rpcService string
 	)
 
+	var (
+		dbCollection      string
+		dbOperation       string
+		dbRedisIndex      string
+		dbSystemNamespace string
+	)
+
 	var http modelpb.HTTP
 	var httpRequest modelpb.HTTPRequest
 	var httpResponse modelpb.HTTPResponse
 	var message modelpb.Message
 	var db modelpb.DB
+	var genAI modelpb.GenAI
 	var destinationService modelpb.DestinationService
 	var serviceTarget modelpb.ServiceTarget
-	var isHTTP, isDatabase, isRPC, isMessaging bool
+	var isHTTP, isDatabase, isRPC, isMessaging, isGenAI bool
 	var samplerType, samplerParam pcommon.Value
 	attributes.Range(func(kDots string, v pcommon.Value) bool {
 		if isJaeger {
@@ -520,7 +700,7 @@ func TranslateSpan(spanKind ptrace.SpanKind, attributes pcommon.Map, event *mode
 			setLabel(k, event, ifaceAttributeValueSlice(v.Slice()))
 		case pcommon.ValueTypeBool:
 			switch kDots {
-			case semconv.AttributeMessagingTempDestination:
+			case semconv.AttributeMessagingTempDestination, attributeMessagingDestinationTemporary, attributeMessagingSourceTemporary:
 				messageTempDestination = v.Bool()
 				fallthrough
 			default:
@@ -530,15 +710,40 @@ func TranslateSpan(spanKind ptrace.SpanKind, attributes pcommon.Map, event *mode
 			setLabel(k, event, v.Double())
 		case pcommon.ValueTypeInt:
 			switch kDots {
-			case "http.status_code":
+			case "http.status_code", attributeHTTPResponseStatusCode:
 				httpResponse.StatusCode = int32(v.Int())
 				http.Response = &httpResponse
 				isHTTP = true
-			case semconv.AttributeNetPeerPort, "peer.port":
+			case semconv.AttributeNetPeerPort, "peer.port", attributeServerPort:
 				netPeerPort = int(v.Int())
+			case attributeNetworkPeerPort:
+				networkPeerPort = int(v.Int())
 			case semconv.AttributeRPCGRPCStatusCode:
 				rpcSystem = "grpc"
 				isRPC = true
+			case attributeMessagingBatchMessageCount, semconv.AttributeMessagingKafkaPartition, attributeMessagingKafkaPartition:
+				// Recorded as labels, as there are no equivalent fields.
+				isMessaging = true
+				setLabel(k, event, v.Int())
+			case attributeKafkaRecordQueueTimeMs:
+				age := v.Int()
+				message.AgeMillis = &age
+				isMessaging = true
+			case semconv.AttributeDBRedisDBIndex:
+				dbRedisIndex = strconv.FormatInt(v.Int(), 10)
+				isDatabase = true
+			case attributeGenAIUsageInputTokens, attributeGenAIUsagePromptTokens:
+				if v.Int() >= 0 {
+					tokens := uint64(v.Int())
+					genAI.InputTokens = &tokens
+				}
+				isGenAI = true
+			case attributeGenAIUsageOutputTokens, attributeGenAIUsageCompletionTokens:
+				if v.Int() >= 0 {
+					tokens := uint64(v.Int())
+					genAI.OutputTokens = &tokens
+				}
+				isGenAI = true
 			default:
 				setLabel(k, event, v.Int())
 			}
@@ -550,19 +755,28 @@ func TranslateSpan(spanKind ptrace.SpanKind, attributes pcommon.Map, event *mode
 			case semconv.AttributeHTTPHost:
 				httpHost = stringval
 				isHTTP = true
-			case semconv.AttributeHTTPScheme:
+			case semconv.AttributeHTTPScheme, attributeURLScheme:
 				httpScheme = stringval
 				isHTTP = true
 			case semconv.AttributeHTTPTarget:
 				httpTarget = stringval
 				isHTTP = true
-			case semconv.AttributeHTTPURL:
+			case semconv.AttributeHTTPURL, attributeURLFull:
 				httpURL = stringval
 				isHTTP = true
-			case semconv.AttributeHTTPMethod:
+			case semconv.AttributeHTTPMethod, attributeHTTPRequestMethod:
 				httpRequest.Method = stringval
 				http.Request = &httpRequest
 				isHTTP = true
+			case attributeHTTPRequestMethodOriginal:
+				httpMethodOriginal = stringval
+
+			// url.*
+			case attributeURLPath:
+				urlPath = stringval
+				isHTTP = true
+			case attributeURLQuery:
+				urlQuery = stringval
 
 			// db.*
 			case "sql.query":
@@ -570,24 +784,40 @@ func TranslateSpan(spanKind ptrace.SpanKind, attributes pcommon.Map, event *mode
 					db.Type = "sql"
 				}
 				fallthrough
-			case semconv.AttributeDBStatement:
+			case semconv.AttributeDBStatement, attributeDBQueryText:
 				// Statement should not be truncated, use original string value.
 				db.Statement = v.Str()
 				isDatabase = true
-			case semconv.AttributeDBName, "db.instance":
+			case semconv.AttributeDBName, "db.instance", attributeDBNamespace:
 				db.Instance = stringval
 				isDatabase = true
-			case semconv.AttributeDBSystem, "db.type":
+			case semconv.AttributeDBCassandraKeyspace, semconv.AttributeDBHBaseNamespace:
+				// Superseded by db.namespace, which takes precedence.
+				dbSystemNamespace = stringval
+				isDatabase = true
+			case semconv.AttributeDBSystem, "db.type", attributeDBSystemName:
 				db.Type = stringval
 				isDatabase = true
 			case semconv.AttributeDBUser:
 				db.UserName = stringval
 				isDatabase = true
+			case semconv.AttributeDBSQLTable, semconv.AttributeDBMongoDBCollection,
+				semconv.AttributeDBCassandraTable, attributeDBCollectionName:
+				dbCollection = stringval
+				modelpb.Labels(event.Labels).Set(k, stringval)
+				isDatabase = true
+			case semconv.AttributeDBOperation, attributeDBOperationName:
+				dbOperation = stringval
+				modelpb.Labels(event.Labels).Set(k, stringval)
+				isDatabase = true
+			case attributeDBResponseStatusCode:
+				modelpb.Labels(event.Labels).Set(k, stringval)
+				isDatabase = true
 
 			// net.*
-			case semconv.AttributeNetPeerName, "peer.hostname":
+			case semconv.AttributeNetPeerName, "peer.hostname", attributeServerAddress:
 				netPeerName = stringval
-			case semconv.AttributeNetPeerIP, "peer.ipv4", "peer.ipv6":
+			case semconv.AttributeNetPeerIP, "peer.ipv4", "peer.ipv6", attributeNetworkPeerAddress:
 				netPeerIP = stringval
 			case "peer.address":
 				peerAddress = stringval
@@ -622,14 +852,38 @@ func TranslateSpan(spanKind ptrace.SpanKind, attributes pcommon.Map, event *mode
 				event.Session.Id = stringval
 
 			// messaging.*
-			case "message_bus.destination", semconv.AttributeMessagingDestination:
-				message.QueueName = stringval
+			//
+			// As for transactions, attributes without an equivalent
+			// modelpb.Message field are recorded as labels.
+			case "message_bus.destination", semconv.AttributeMessagingDestination, attributeMessagingDestinationName:
+				messageQueue.destination = stringval
+				isMessaging = true
+			case attributeMessagingSourceName:
+				messageQueue.source = stringval
 				isMessaging = true
+			case semconv.AttributeMessagingURL, attributeAWSSQSQueueURL:
+				messageQueue.url = stringval
+				modelpb.Labels(event.Labels).Set(k, stringval)
 			case semconv.AttributeMessagingOperation:
 				messageOperation = stringval
+				if messageOperation == "publish" {
+					// "publish" was introduced in v1.17, and is
+					// equivalent to the Elastic APM "send" action.
+					messageOperation = "send"
+				}
 				isMessaging = true
 			case semconv.AttributeMessagingSystem:
-				messageSystem = stringval
+				messageQueue.system = stringval
+				isMessaging = true
+			case semconv.AttributeMessagingRabbitmqRoutingKey, attributeMessagingRabbitMQRoutingKey:
+				message.RoutingKey = stringval
+				isMessaging = true
+			case semconv.AttributeMessagingMessageID, attributeMessagingMessageID,
+				semconv.AttributeMessagingConversationID, attributeMessagingConversationID,
+				semconv.AttributeMessagingKafkaConsumerGroup, attributeMessagingKafkaConsumerGroup,
+				semconv.AttributeMessagingKafkaMessageKey, attributeMessagingKafkaMessageKey:
+				// Recorded as labels, as there are no equivalent fields.
+				modelpb.Labels(event.Labels).Set(k, stringval)
 				isMessaging = true
 
 			// rpc.*
@@ -648,6 +902,20 @@ func TranslateSpan(spanKind ptrace.SpanKind, attributes pcommon.Map, event *mode
 				isRPC = true
 			case semconv.AttributeRPCMethod:
 
+			// gen_ai.*
+			case attributeGenAISystem, attributeGenAIProviderName:
+				genAI.System = stringval
+				isGenAI = true
+			case attributeGenAIOperationName:
+				genAI.OperationName = stringval
+				isGenAI = true
+			case attributeGenAIRequestModel:
+				genAI.RequestModel = stringval
+				isGenAI = true
+			case attributeGenAIResponseModel:
+				genAI.ResponseModel = stringval
+				isGenAI = true
+
 			// miscellaneous
 			case "span.kind": // filter out
 			case semconv.AttributePeerService:
@@ -668,11 +936,21 @@ func TranslateSpan(spanKind ptrace.SpanKind, attributes pcommon.Map, event *mode
 	}
 
 	destPort := netPeerPort
+	if destPort == 0 {
+		destPort = networkPeerPort
+	}
 	destAddr := netPeerName
 	if destAddr == "" {
 		destAddr = netPeerIP
 	}
 
+	if httpTarget == "" && urlPath != "" {
+		httpTarget = urlPath
+		if urlQuery != "" {
+			httpTarget += "?" + urlQuery
+		}
+	}
+
 	var fullURL *url.URL
 	if httpURL != "" {
 		fullURL, _ = url.Parse(httpURL)
@@ -717,6 +995,9 @@ func TranslateSpan(spanKind ptrace.SpanKind, attributes pcommon.Map, event *mode
 	}
 
 	if isHTTP {
+		if httpRequest.Method == "_OTHER" && httpMethodOriginal != "" {
+			httpRequest.Method = httpMethodOriginal
+		}
 		if httpResponse.StatusCode > 0 && event.Event.Outcome == outcomeUnknown {
 			event.Event.Outcome = clientHTTPStatusCodeOutcome(int(httpResponse.StatusCode))
 		}
@@ -727,13 +1008,47 @@ func TranslateSpan(spanKind ptrace.SpanKind, attributes pcommon.Map, event *mode
 		event.Url.Original = httpURL
 	}
 	if isDatabase {
+		if db.Instance == "" {
+			db.Instance = dbSystemNamespace
+			if db.Instance == "" {
+				// Redis databases are identified by their index.
+				db.Instance = dbRedisIndex
+			}
+		}
+		if isGenericDBSpanName(event.Span.Name, db.Type, dbOperation) {
+			if name := dbSpanName(dbOperation, dbCollection); name != "" {
+				event.Span.Name = name
+			}
+		}
 		event.Span.Db = &db
 	}
 	if isMessaging {
+		message.QueueName = messageQueue.name()
 		event.Span.Message = &message
 	}
+	if isGenAI {
+		event.Span.GenAi = &genAI
+	}
 
 	switch {
+	case isGenAI:
+		// Generative AI requests are usually made over HTTP, but are
+		// classified by the more specific gen_ai.* attributes.
+		event.Span.Type = "genai"
+		event.Span.Subtype = genAI.System
+		event.Span.Action = genAI.OperationName
+		serviceTarget.Type = event.Span.Type
+		if event.Span.Subtype != "" {
+			serviceTarget.Type = event.Span.Subtype
+			if destinationService.Name == "" {
+				destinationService.Name = event.Span.Subtype
+				destinationService.Resource = event.Span.Subtype
+			}
+		}
+		serviceTarget.Name = genAI.RequestModel
+		if serviceTarget.Name == "" {
+			serviceTarget.Name = genAI.ResponseModel
+		}
 	case isDatabase:
 		event.Span.Type = "db"
 		event.Span.Subtype = db.Type
@@ -752,7 +1067,7 @@ func TranslateSpan(spanKind ptrace.SpanKind, attributes pcommon.Map, event *mode
 		}
 	case isMessaging:
 		event.Span.Type = "messaging"
-		event.Span.Subtype = messageSystem
+		event.Span.Subtype = messageQueue.system
 		if messageOperation == "" && spanKind == ptrace.SpanKindProducer {
 			messageOperation = "send"
 		}
@@ -845,6 +1160,49 @@ func TranslateSpan(spanKind ptrace.SpanKind, attributes pcommon.Map, event *mode
 	}
 }
 
+// isGenericDBSpanName reports whether a database span name carries no
+// more information than the span's attributes, such as when instrumentation
+// names spans after the database system or operation alone.
+func isGenericDBSpanName(name, system, operation string) bool {
+	return name == "" || strings.EqualFold(name, system) || strings.EqualFold(name, operation)
+}
+
+// dbSpanName returns a span name for a database operation on a collection
+// or table, following the OpenTelemetry database semantic conventions.
+func dbSpanName(operation, collection string) string {
+	if operation == "" || collection == "" {
+		return operation
+	}
+	return operation + " " + collection
+}
+
+// messageQueue holds the attributes identifying the queue or topic
+// to which a message was sent, or from which it was received.
+type messageQueue struct {
+	destination string
+	source      string
+	url         string
+	system      string
+}
+
+// name returns the name of the queue, preferring the destination to the
+// source. For Amazon SQS, the name is otherwise taken from the queue URL.
+func (q messageQueue) name() string {
+	switch {
+	case q.destination != "":
+		return q.destination
+	case q.source != "":
+		return q.source
+	}
+	switch q.system {
+	case "aws_sqs", "AmazonSQS":
+		if u, err := url.Parse(q.url); err == nil && strings.Trim(u.Path, "/") != "" {
+			return truncate(path.Base(u.Path))
+		}
+	}
+	return ""
+}
+
 func parseSamplerAttributes(samplerType, samplerParam pcommon.Value, event *modelpb.APMEvent) {
 	switch samplerType := samplerType.Str(); samplerType {
 	case "probabilistic":
@@ -37,11 +37,13 @@ package otlp_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -53,6 +55,8 @@ import (
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	semconv "go.opentelemetry.io/collector/semconv/v1.5.0"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 	"golang.org/x/sync/semaphore"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/protobuf/testing/protocmp"
@@ -206,6 +210,36 @@ func TestHTTPTransactionURL(t *testing.T) {
 			"http.url": "https://testing.invalid:80/foo?bar",
 		})
 	})
+	t.Run("url.scheme_server.address_server.port_url.path_url.query", func(t *testing.T) {
+		test(t, &modelpb.URL{
+			Scheme:   "https",
+			Original: "/foo?bar",
+			Full:     "https://testing.invalid:80/foo?bar",
+			Path:     "/foo",
+			Query:    "bar",
+			Domain:   "testing.invalid",
+			Port:     80,
+		}, map[string]interface{}{
+			"url.scheme":     "https",
+			"server.address": "testing.invalid",
+			"server.port":    80,
+			"url.path":       "/foo",
+			"url.query":      "bar",
+		})
+	})
+	t.Run("url.full", func(t *testing.T) {
+		test(t, &modelpb.URL{
+			Scheme:   "https",
+			Original: "https://testing.invalid:80/foo?bar",
+			Full:     "https://testing.invalid:80/foo?bar",
+			Path:     "/foo",
+			Query:    "bar",
+			Domain:   "testing.invalid",
+			Port:     80,
+		}, map[string]interface{}{
+			"url.full": "https://testing.invalid:80/foo?bar",
+		})
+	})
 	t.Run("host_no_port", func(t *testing.T) {
 		test(t, &modelpb.URL{
 			Scheme:   "https",
@@ -275,6 +309,21 @@ func TestHTTPSpanURL(t *testing.T) {
 			"http.target":   "/foo?bar",
 		})
 	})
+	t.Run("url.full", func(t *testing.T) {
+		test(t, "https://testing.invalid:80/foo?bar", map[string]interface{}{
+			"url.full": "https://testing.invalid:80/foo?bar",
+		})
+	})
+	t.Run("url.scheme_server.address_server.port_url.path_url.query", func(t *testing.T) {
+		test(t, "https://testing.invalid:80/foo?bar", map[string]interface{}{
+			"url.scheme":           "https",
+			"server.address":       "testing.invalid",
+			"network.peer.address": "::1", // server.address preferred
+			"server.port":          80,
+			"url.path":             "/foo",
+			"url.query":            "bar",
+		})
+	})
 	t.Run("scheme_netpeerip_netpeerport_target", func(t *testing.T) {
 		test(t, "https://[::1]:80/foo?bar", map[string]interface{}{
 			"http.scheme":   "https",
@@ -365,6 +414,35 @@ func TestHTTPSpanDestination(t *testing.T) {
 			"http.target":   "/foo?bar",
 		})
 	})
+	t.Run("url.full", func(t *testing.T) {
+		test(t, &modelpb.Destination{
+			Address: "testing.invalid",
+			Port:    443,
+		}, &modelpb.DestinationService{
+			Type:     "external",
+			Name:     "https://testing.invalid",
+			Resource: "testing.invalid:443",
+		}, map[string]interface{}{
+			"url.full":       "https://testing.invalid/foo?bar",
+			"server.address": "testing.invalid",
+			"server.port":    443,
+		})
+	})
+	t.Run("network.peer.address_network.peer.port", func(t *testing.T) {
+		test(t, &modelpb.Destination{
+			Address: "::1",
+			Port:    444,
+		}, &modelpb.DestinationService{
+			Type:     "external",
+			Name:     "https://[::1]:444",
+			Resource: "[::1]:444",
+		}, map[string]interface{}{
+			"url.scheme":           "https",
+			"network.peer.address": "::1",
+			"network.peer.port":    444,
+			"url.path":             "/foo",
+		})
+	})
 }
 
 func TestHTTPTransactionSource(t *testing.T) {
@@ -399,6 +477,12 @@ func TestHTTPTransactionSource(t *testing.T) {
 			"net.peer.ip": "192.168.0.1",
 		})
 	})
+	t.Run("network.peer.address_port", func(t *testing.T) {
+		test(t, "", "192.168.0.1", 1234, map[string]interface{}{
+			"network.peer.address": "192.168.0.1",
+			"network.peer.port":    1234,
+		})
+	})
 	t.Run("net.peer.ip_name", func(t *testing.T) {
 		test(t, "source.domain", "192.168.0.1", 0, map[string]interface{}{
 			"net.peer.name": "source.domain",
@@ -412,13 +496,21 @@ func TestHTTPTransactionFlavor(t *testing.T) {
 		"http.flavor": "1.1",
 	})
 	assert.Equal(t, "1.1", event.Http.Version)
+
+	event = transformTransactionWithAttributes(t, map[string]interface{}{
+		"http.request.method":      "GET",
+		"network.protocol.version": "2",
+	})
+	assert.Equal(t, "2", event.Http.Version)
 }
 
 func TestHTTPTransactionUserAgent(t *testing.T) {
-	event := transformTransactionWithAttributes(t, map[string]interface{}{
-		"http.user_agent": "Foo/bar (baz)",
-	})
-	assert.Equal(t, &modelpb.UserAgent{Original: "Foo/bar (baz)"}, event.UserAgent)
+	for _, key := range []string{"http.user_agent", "user_agent.original"} {
+		event := transformTransactionWithAttributes(t, map[string]interface{}{
+			key: "Foo/bar (baz)",
+		})
+		assert.Equal(t, &modelpb.UserAgent{Original: "Foo/bar (baz)"}, event.UserAgent)
+	}
 }
 
 func TestHTTPTransactionClientIP(t *testing.T) {
@@ -429,13 +521,117 @@ func TestHTTPTransactionClientIP(t *testing.T) {
 	})
 	assert.Equal(t, &modelpb.Source{Ip: "1.2.3.4", Port: 5678}, event.Source)
 	assert.Equal(t, &modelpb.Client{Ip: "9.10.11.12"}, event.Client)
+
+	event = transformTransactionWithAttributes(t, map[string]interface{}{
+		"network.peer.address": "1.2.3.4",
+		"network.peer.port":    5678,
+		"client.address":       "9.10.11.12",
+	})
+	assert.Equal(t, &modelpb.Source{Ip: "1.2.3.4", Port: 5678}, event.Source)
+	assert.Equal(t, &modelpb.Client{Ip: "9.10.11.12"}, event.Client)
 }
 
 func TestHTTPTransactionStatusCode(t *testing.T) {
-	event := transformTransactionWithAttributes(t, map[string]interface{}{
-		"http.status_code": 200,
-	})
-	assert.Equal(t, int32(200), event.Http.Response.StatusCode)
+	for _, key := range []string{"http.status_code", "http.response.status_code"} {
+		event := transformTransactionWithAttributes(t, map[string]interface{}{
+			key: 200,
+		})
+		assert.Equal(t, int32(200), event.Http.Response.StatusCode)
+	}
+}
+
+func TestHTTPRequestMethodOriginal(t *testing.T) {
+	attrs := map[string]interface{}{
+		"http.request.method":          "_OTHER",
+		"http.request.method_original": "PURGE",
+	}
+	event := transformTransactionWithAttributes(t, attrs)
+	assert.Equal(t, "PURGE", event.Http.Request.Method)
+	event = transformSpanWithAttributes(t, attrs)
+	assert.Equal(t, "PURGE", event.Http.Request.Method)
+}
+
+func TestHTTPSemanticConventions(t *testing.T) {
+	// Each generation of the HTTP semantic conventions should produce
+	// the same transaction and span.
+	for _, tc := range []struct {
+		name   string
+		server map[string]interface{}
+		client map[string]interface{}
+	}{{
+		name: "semconv_v1.5.0",
+		server: map[string]interface{}{
+			"http.method":      "GET",
+			"http.scheme":      "https",
+			"http.target":      "/users?id=1",
+			"net.host.name":    "api.testing.invalid",
+			"net.host.port":    8443,
+			"net.peer.ip":      "10.0.0.1",
+			"net.peer.port":    5678,
+			"http.client_ip":   "192.0.2.1",
+			"http.status_code": 503,
+			"http.user_agent":  "Go-http-client/1.1",
+			"http.flavor":      "1.1",
+		},
+		client: map[string]interface{}{
+			"http.method":      "GET",
+			"http.url":         "https://backend.testing.invalid/users?id=1",
+			"net.peer.name":    "backend.testing.invalid",
+			"net.peer.ip":      "10.0.0.2",
+			"net.peer.port":    443,
+			"http.status_code": 404,
+		},
+	}, {
+		name: "semconv_stable",
+		server: map[string]interface{}{
+			"http.request.method":       "GET",
+			"url.scheme":                "https",
+			"url.path":                  "/users",
+			"url.query":                 "id=1",
+			"server.address":            "api.testing.invalid",
+			"server.port":               8443,
+			"network.peer.address":      "10.0.0.1",
+			"network.peer.port":         5678,
+			"client.address":            "192.0.2.1",
+			"http.response.status_code": 503,
+			"user_agent.original":       "Go-http-client/1.1",
+			"network.protocol.version":  "1.1",
+		},
+		client: map[string]interface{}{
+			"http.request.method":       "GET",
+			"url.full":                  "https://backend.testing.invalid/users?id=1",
+			"server.address":            "backend.testing.invalid",
+			"network.peer.address":      "10.0.0.2",
+			"server.port":               443,
+			"http.response.status_code": 404,
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			startTime := time.Unix(123, 0).UTC()
+			traces, spans := newTracesSpans()
+			server := spans.Spans().AppendEmpty()
+			server.SetName("GET /users")
+			server.SetKind(ptrace.SpanKindServer)
+			server.SetTraceID(pcommon.TraceID{1})
+			server.SetSpanID(pcommon.SpanID{2})
+			server.SetStartTimestamp(pcommon.NewTimestampFromTime(startTime))
+			server.SetEndTimestamp(pcommon.NewTimestampFromTime(startTime.Add(time.Second)))
+			server.Attributes().FromRaw(tc.server)
+
+			client := spans.Spans().AppendEmpty()
+			client.SetName("GET")
+			client.SetKind(ptrace.SpanKindClient)
+			client.SetTraceID(pcommon.TraceID{1})
+			client.SetSpanID(pcommon.SpanID{3})
+			client.SetParentSpanID(pcommon.SpanID{2})
+			client.SetStartTimestamp(pcommon.NewTimestampFromTime(startTime.Add(time.Millisecond)))
+			client.SetEndTimestamp(pcommon.NewTimestampFromTime(startTime.Add(500 * time.Millisecond)))
+			client.Attributes().FromRaw(tc.client)
+
+			docs := encodeBatch(t, transformTraces(t, traces))
+			approveEventDocs(t, "http_"+tc.name, docs)
+		})
+	}
 }
 
 func TestDatabaseSpan(t *testing.T) {
@@ -482,6 +678,162 @@ func TestDatabaseSpan(t *testing.T) {
 	}, event.Span.DestinationService, protocmp.Transform()))
 }
 
+func TestDatabaseSpanSemconv126(t *testing.T) {
+	event := transformSpanWithAttributes(t, map[string]interface{}{
+		"db.system":               "postgresql",
+		"db.namespace":            "ShopDb",
+		"db.collection.name":      "orders",
+		"db.operation.name":       "SELECT",
+		"db.query.text":           "SELECT * FROM orders",
+		"db.response.status_code": "08P01",
+		"server.address":          "shopdb.example.com",
+		"server.port":             5432,
+	}, func(s ptrace.Span) {
+		s.SetName("postgresql")
+	})
+
+	assert.Equal(t, "db", event.Span.Type)
+	assert.Equal(t, "postgresql", event.Span.Subtype)
+	assert.Equal(t, "SELECT orders", event.Span.Name)
+	assert.Equal(t, &modelpb.DB{
+		Instance:  "ShopDb",
+		Statement: "SELECT * FROM orders",
+		Type:      "postgresql",
+	}, event.Span.Db)
+	assert.Equal(t, modelpb.Labels{
+		"db_collection_name":      {Value: "orders"},
+		"db_operation_name":       {Value: "SELECT"},
+		"db_response_status_code": {Value: "08P01"},
+	}, modelpb.Labels(event.Labels))
+	assert.Equal(t, &modelpb.Destination{
+		Address: "shopdb.example.com",
+		Port:    5432,
+	}, event.Destination)
+	assert.Empty(t, cmp.Diff(&modelpb.ServiceTarget{
+		Type: "postgresql",
+		Name: "ShopDb",
+	}, event.Service.Target, protocmp.Transform()))
+}
+
+func TestDatabaseSpanName(t *testing.T) {
+	test := func(t *testing.T, name, expected string, attrs map[string]interface{}) {
+		t.Helper()
+		event := transformSpanWithAttributes(t, attrs, func(s ptrace.Span) {
+			s.SetName(name)
+		})
+		assert.Equal(t, expected, event.Span.Name)
+	}
+
+	t.Run("system_name", func(t *testing.T) {
+		test(t, "mongodb", "find users", map[string]interface{}{
+			"db.system":             "mongodb",
+			"db.operation":          "find",
+			"db.mongodb.collection": "users",
+		})
+	})
+	t.Run("operation_name", func(t *testing.T) {
+		test(t, "GET", "GET", map[string]interface{}{
+			"db.system":         "redis",
+			"db.operation.name": "GET",
+		})
+	})
+	t.Run("empty_name", func(t *testing.T) {
+		test(t, "", "SELECT orders", map[string]interface{}{
+			"db.system":    "mysql",
+			"db.operation": "SELECT",
+			"db.sql.table": "orders",
+		})
+	})
+	t.Run("specific_name", func(t *testing.T) {
+		test(t, "SELECT orders WHERE id = ?", "SELECT orders WHERE id = ?", map[string]interface{}{
+			"db.system":          "mysql",
+			"db.operation.name":  "SELECT",
+			"db.collection.name": "orders",
+		})
+	})
+}
+
+func TestDatabaseSpanInstance(t *testing.T) {
+	test := func(t *testing.T, expectedInstance string, attrs map[string]interface{}) {
+		t.Helper()
+		event := transformSpanWithAttributes(t, attrs)
+		assert.Equal(t, expectedInstance, event.Span.Db.Instance)
+		assert.Equal(t, expectedInstance, event.Service.Target.Name)
+	}
+
+	t.Run("redis_database_index", func(t *testing.T) {
+		test(t, "2", map[string]interface{}{
+			"db.system":               "redis",
+			"db.redis.database_index": 2,
+		})
+	})
+	t.Run("cassandra_keyspace", func(t *testing.T) {
+		test(t, "ks", map[string]interface{}{
+			"db.system":             "cassandra",
+			"db.cassandra.keyspace": "ks",
+		})
+	})
+	t.Run("namespace_preferred", func(t *testing.T) {
+		test(t, "ns", map[string]interface{}{
+			"db.system":               "redis",
+			"db.namespace":            "ns",
+			"db.redis.database_index": 2,
+		})
+	})
+}
+
+func TestGenAISpan(t *testing.T) {
+	event := transformSpanWithAttributes(t, map[string]interface{}{
+		"gen_ai.system":              "openai",
+		"gen_ai.operation.name":      "chat",
+		"gen_ai.request.model":       "gpt-4",
+		"gen_ai.response.model":      "gpt-4-0613",
+		"gen_ai.usage.input_tokens":  100,
+		"gen_ai.usage.output_tokens": 20,
+		"http.request.method":        "POST",
+		"url.full":                   "https://api.openai.com/v1/chat/completions",
+	}, func(s ptrace.Span) {
+		s.SetKind(ptrace.SpanKindClient)
+	})
+
+	assert.Equal(t, "genai", event.Span.Type)
+	assert.Equal(t, "openai", event.Span.Subtype)
+	assert.Equal(t, "chat", event.Span.Action)
+	inputTokens, outputTokens := uint64(100), uint64(20)
+	assert.Empty(t, cmp.Diff(&modelpb.GenAI{
+		System:        "openai",
+		OperationName: "chat",
+		RequestModel:  "gpt-4",
+		ResponseModel: "gpt-4-0613",
+		InputTokens:   &inputTokens,
+		OutputTokens:  &outputTokens,
+	}, event.Span.GenAi, protocmp.Transform()))
+	assert.Empty(t, event.Labels)
+	assert.Empty(t, event.NumericLabels)
+	assert.Empty(t, cmp.Diff(&modelpb.ServiceTarget{
+		Type: "openai",
+		Name: "gpt-4",
+	}, event.Service.Target, protocmp.Transform()))
+	assert.Empty(t, cmp.Diff(&modelpb.DestinationService{
+		Type:     "genai",
+		Name:     "openai",
+		Resource: "openai",
+	}, event.Span.DestinationService, protocmp.Transform()))
+}
+
+func TestGenAISpanDeprecatedUsage(t *testing.T) {
+	event := transformSpanWithAttributes(t, map[string]interface{}{
+		"gen_ai.system":                  "anthropic",
+		"gen_ai.response.model":          "claude",
+		"gen_ai.usage.prompt_tokens":     7,
+		"gen_ai.usage.completion_tokens": 8,
+	})
+	assert.Equal(t, "genai", event.Span.Type)
+	assert.Equal(t, uint64(7), event.Span.GenAi.GetInputTokens())
+	assert.Equal(t, uint64(8), event.Span.GenAi.GetOutputTokens())
+	assert.Equal(t, "claude", event.Service.Target.Name)
+}
+
 func TestInstrumentationLibrary(t *testing.T) {
 	traces, spans := newTracesSpans()
 	spans.Scope().SetName("library-name")
@@ -580,6 +932,145 @@ func TestMessagingSpan(t *testing.T) {
 	}, event.Span.DestinationService, protocmp.Transform()))
 }
 
+func TestMessagingTransactionSemconv117(t *testing.T) {
+	setConsumer := func(s ptrace.Span) { s.SetKind(ptrace.SpanKindConsumer) }
+
+	event := transformTransactionWithAttributes(t, map[string]interface{}{
+		"messaging.system":                           "rabbitmq",
+		"messaging.destination.name":                 "myExchange",
+		"messaging.rabbitmq.destination.routing_key": "myKey",
+		"messaging.message.id":                       "abc123",
+		"messaging.message.conversation_id":          "conv",
+	}, setConsumer)
+	assert.Equal(t, "messaging", event.Transaction.Type)
+	assert.Equal(t, &modelpb.Message{
+		QueueName:  "myExchange",
+		RoutingKey: "myKey",
+	}, event.Transaction.Message)
+	assert.Equal(t, modelpb.Labels{
+		"messaging_system":                  {Value: "rabbitmq"},
+		"messaging_message_id":              {Value: "abc123"},
+		"messaging_message_conversation_id": {Value: "conv"},
+	}, modelpb.Labels(event.Labels))
+
+	event = transformTransactionWithAttributes(t, map[string]interface{}{
+		"messaging.source.name":                 "myTopic",
+		"messaging.kafka.consumer.group":        "myGroup",
+		"messaging.kafka.destination.partition": 3,
+		"messaging.batch.message_count":         10,
+		"kafka.record.queue_time_ms":            25,
+	}, setConsumer)
+	assert.Equal(t, "messaging", event.Transaction.Type)
+	age := int64(25)
+	assert.Equal(t, &modelpb.Message{QueueName: "myTopic", AgeMillis: &age}, event.Transaction.Message)
+	assert.Equal(t, modelpb.Labels{
+		"messaging_kafka_consumer_group": {Value: "myGroup"},
+	}, modelpb.Labels(event.Labels))
+	assert.Equal(t, modelpb.NumericLabels{
+		"messaging_kafka_destination_partition": {Value: 3},
+		"messaging_batch_message_count":         {Value: 10},
+	}, modelpb.NumericLabels(event.NumericLabels))
+}
+
+func TestFaaSTransaction(t *testing.T) {
+	traces, spans := newTracesSpans()
+	traces.ResourceSpans().At(0).Resource().Attributes().FromRaw(map[string]interface{}{
+		"cloud.provider":    "aws",
+		"cloud.platform":    "aws_lambda",
+		"cloud.resource_id": "arn:aws:lambda:us-east-1:123456:function:my-function",
+		"faas.name":         "my-function",
+		"faas.version":      "$LATEST",
+		"faas.instance":     "2023/01/01/[$LATEST]abc123",
+	})
+	otelSpan := spans.Spans().AppendEmpty()
+	otelSpan.SetTraceID(pcommon.TraceID{1})
+	otelSpan.SetSpanID(pcommon.SpanID{2})
+	otelSpan.SetKind(ptrace.SpanKindServer)
+	otelSpan.Attributes().FromRaw(map[string]interface{}{
+		"faas.trigger":       "http",
+		"faas.invocation_id": "af9c3a1b-4e7d-4f5e-9a2b-1c3d5e7f9a0b",
+		"faas.coldstart":     true,
+	})
+	event := (*transformTraces(t, traces))[0]
+
+	coldStart := true
+	assert.Empty(t, cmp.Diff(&modelpb.Faas{
+		Id:          "arn:aws:lambda:us-east-1:123456:function:my-function",
+		Name:        "my-function",
+		Version:     "$LATEST",
+		TriggerType: "http",
+		Execution:   "af9c3a1b-4e7d-4f5e-9a2b-1c3d5e7f9a0b",
+		ColdStart:   &coldStart,
+	}, event.Faas, protocmp.Transform()))
+	assert.Equal(t, &modelpb.Cloud{Provider: "aws", ServiceName: "lambda"}, event.Cloud)
+	assert.Equal(t, "my-function", event.Service.Name)
+	assert.Equal(t, "$LATEST", event.Service.Version)
+	assert.Equal(t, "2023/01/01/[$LATEST]abc123", event.Service.Node.Name)
+	assert.Empty(t, event.Labels)
+	assert.Empty(t, event.NumericLabels)
+}
+
+func TestMessagingSpanSemconv117(t *testing.T) {
+	test := func(t *testing.T, expectedAction string, expectedMessage *modelpb.Message, expectedTarget *modelpb.ServiceTarget, attrs map[string]interface{}) {
+		t.Helper()
+		event := transformSpanWithAttributes(t, attrs, func(s ptrace.Span) {
+			s.SetKind(ptrace.SpanKindProducer)
+		})
+		assert.Equal(t, "messaging", event.Span.Type)
+		assert.Equal(t, expectedAction, event.Span.Action)
+		assert.Empty(t, cmp.Diff(expectedMessage, event.Span.Message, protocmp.Transform()))
+		assert.Empty(t, cmp.Diff(expectedTarget, event.Service.Target, protocmp.Transform()))
+	}
+
+	t.Run("kafka", func(t *testing.T) {
+		test(t, "send", &modelpb.Message{QueueName: "myTopic"}, &modelpb.ServiceTarget{
+			Type: "kafka",
+			Name: "myTopic",
+		}, map[string]interface{}{
+			"messaging.system":                      "kafka",
+			"messaging.operation":                   "publish",
+			"messaging.destination.name":            "myTopic",
+			"messaging.kafka.destination.partition": 1,
+			"messaging.kafka.message.key":           "myKey",
+		})
+	})
+	t.Run("rabbitmq_temporary", func(t *testing.T) {
+		test(t, "send", &modelpb.Message{
+			QueueName:  "amq.gen-123",
+			RoutingKey: "myKey",
+		}, &modelpb.ServiceTarget{
+			Type: "rabbitmq",
+		}, map[string]interface{}{
+			"messaging.system":                           "rabbitmq",
+			"messaging.destination.name":                 "amq.gen-123",
+			"messaging.destination.temporary":            true,
+			"messaging.rabbitmq.destination.routing_key": "myKey",
+		})
+	})
+	t.Run("sqs_queue_url", func(t *testing.T) {
+		test(t, "send", &modelpb.Message{QueueName: "myQueue"}, &modelpb.ServiceTarget{
+			Type: "aws_sqs",
+			Name: "myQueue",
+		}, map[string]interface{}{
+			"messaging.system":  "aws_sqs",
+			"aws.sqs.queue.url": "https://sqs.us-east-1.amazonaws.com/123456789012/myQueue",
+		})
+	})
+	t.Run("destination_preferred_to_source", func(t *testing.T) {
+		age := int64(25)
+		test(t, "receive", &modelpb.Message{QueueName: "myTopic", AgeMillis: &age}, &modelpb.ServiceTarget{
+			Type: "kafka",
+			Name: "myTopic",
+		}, map[string]interface{}{
+			"messaging.system":           "kafka",
+			"messaging.operation":        "receive",
+			"messaging.source.name":      "otherTopic",
+			"messaging.destination.name": "myTopic",
+			"kafka.record.queue_time_ms": 25,
+		})
+	})
+}
+
 func TestMessagingSpan_DestinationResource(t *testing.T) {
 	test := func(t *testing.T, expectedDestination *modelpb.Destination, expectedDestinationService *modelpb.DestinationService, attrs map[string]interface{}) {
 		t.Helper()
@@ -891,6 +1382,264 @@ func TestConsumeTracesSemaphore(t *testing.T) {
 	assert.NoError(t, consumer.ConsumeTraces(context.Background(), traces))
 }
 
+func TestConsumeTracesAsync(t *testing.T) {
+	traces, spans := newTracesSpans()
+	spans.Spans().AppendEmpty().SetSpanID(pcommon.SpanID{1})
+
+	processCh := make(chan struct{})
+	var processed int
+	processor := modelpb.ProcessBatchFunc(func(ctx context.Context, batch *modelpb.Batch) error {
+		<-processCh
+		processed += len(*batch)
+		return nil
+	})
+	consumer := otlp.NewConsumer(otlp.ConsumerConfig{
+		Processor: processor,
+		Semaphore: &fakeSemaphore{available: 0},
+		Async:     true,
+	})
+
+	// The semaphore is full, so the request is rejected without blocking.
+	result, err := consumer.ConsumeTracesWithResult(context.Background(), traces)
+	assert.Equal(t, otlp.ErrQueueFull, err)
+	assert.Zero(t, result)
+
+	// Once the semaphore has capacity, the request is accepted
+	// immediately and processed in the background.
+	sem := &fakeSemaphore{available: 1, released: make(chan struct{}, 1)}
+	consumer = otlp.NewConsumer(otlp.ConsumerConfig{
+		Processor: processor,
+		Semaphore: sem,
+		Async:     true,
+	})
+	result, err = consumer.ConsumeTracesWithResult(context.Background(), traces)
+	require.NoError(t, err)
+	assert.Equal(t, otlp.ConsumeTracesResult{AcceptedSpans: 1}, result)
+	assert.Equal(t, int64(0), sem.Available())
+
+	// Requests are rejected while the first is being processed.
+	err = consumer.ConsumeTraces(context.Background(), traces)
+	assert.Equal(t, otlp.ErrQueueFull, err)
+
+	close(processCh)
+	select {
+	case <-sem.released:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for semaphore release")
+	}
+	assert.Equal(t, int64(1), sem.Available())
+	assert.Equal(t, 1, processed)
+	assert.Zero(t, sem.Acquires())
+}
+
+func TestConsumeTracesAsyncMaxBatchSize(t *testing.T) {
+	traces := newTracesMultipleScopes(3, 2, 4)
+
+	var batchSizes []int
+	core, logs := observer.New(zap.ErrorLevel)
+	sem := &fakeSemaphore{available: 1, released: make(chan struct{}, 1)}
+	consumer := otlp.NewConsumer(otlp.ConsumerConfig{
+		Processor: modelpb.ProcessBatchFunc(func(ctx context.Context, batch *modelpb.Batch) error {
+			batchSizes = append(batchSizes, len(*batch))
+			if len(batchSizes)%2 == 0 {
+				// Processing errors are logged, and the remaining
+				// batches are still processed.
+				return errors.New("queue full")
+			}
+			return nil
+		}),
+		Logger:       zap.New(core),
+		Semaphore:    sem,
+		MaxBatchSize: 2,
+		Async:        true,
+	})
+	result, err := consumer.ConsumeTracesWithResult(context.Background(), traces)
+	require.NoError(t, err)
+	assert.Equal(t, otlp.ConsumeTracesResult{AcceptedSpans: 9}, result)
+
+	select {
+	case <-sem.released:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for semaphore release")
+	}
+	assert.Equal(t, []int{2, 2, 2, 2, 1}, batchSizes)
+	assert.Equal(t, int64(1), sem.Available())
+	assert.Equal(t, 2, logs.FilterMessage("failed handling async request").Len())
+}
+
+func TestConsumeTracesWithResult(t *testing.T) {
+	traces, spans := newTracesSpans()
+	for i := 0; i < 3; i++ {
+		otelSpan := spans.Spans().AppendEmpty()
+		otelSpan.SetTraceID(pcommon.TraceID{1})
+		otelSpan.SetSpanID(pcommon.SpanID{byte(i + 1)})
+	}
+
+	processErr := errors.New("queue full")
+	var failProcessing bool
+	consumer := otlp.NewConsumer(otlp.ConsumerConfig{
+		Processor: modelpb.ProcessBatchFunc(func(ctx context.Context, batch *modelpb.Batch) error {
+			if failProcessing {
+				return processErr
+			}
+			return nil
+		}),
+		Semaphore: semaphore.NewWeighted(1),
+	})
+
+	result, err := consumer.ConsumeTracesWithResult(context.Background(), traces)
+	require.NoError(t, err)
+	assert.Equal(t, otlp.ConsumeTracesResult{AcceptedSpans: 3}, result)
+	assert.Zero(t, result.ExportResponse().PartialSuccess().RejectedSpans())
+	assert.Equal(t, int64(3), consumer.Stats().AcceptedSpans)
+
+	// Processing errors fail the whole request, so that clients may retry.
+	failProcessing = true
+	result, err = consumer.ConsumeTracesWithResult(context.Background(), traces)
+	assert.Equal(t, processErr, err)
+	assert.Zero(t, result)
+}
+
+func TestConsumeTracesMaxBatchSize(t *testing.T) {
+	traces := newTracesMultipleScopes(3, 2, 4)
+
+	var batchSizes []int
+	var spanIDs []string
+	consumer := otlp.NewConsumer(otlp.ConsumerConfig{
+		Processor: modelpb.ProcessBatchFunc(func(ctx context.Context, batch *modelpb.Batch) error {
+			batchSizes = append(batchSizes, len(*batch))
+			for _, event := range *batch {
+				spanIDs = append(spanIDs, event.Span.Id)
+			}
+			return nil
+		}),
+		Semaphore:    semaphore.NewWeighted(1),
+		MaxBatchSize: 2,
+	})
+
+	result, err := consumer.ConsumeTracesWithResult(context.Background(), traces)
+	require.NoError(t, err)
+	assert.Equal(t, otlp.ConsumeTracesResult{AcceptedSpans: 9}, result)
+	assert.Equal(t, []int{2, 2, 2, 2, 1}, batchSizes)
+	assert.Equal(t, []string{
+		"0100000000000000", "0200000000000000", "0300000000000000",
+		"0400000000000000", "0500000000000000", "0600000000000000",
+		"0700000000000000", "0800000000000000", "0900000000000000",
+	}, spanIDs)
+}
+
+func TestConsumeTracesMaxBatchBytes(t *testing.T) {
+	traces := newTracesMultipleScopes(3, 2, 4)
+
+	// Measure the encoded size of a single event, so we can
+	// configure a limit which admits exactly two per batch.
+	var eventSize int
+	consumer := otlp.NewConsumer(otlp.ConsumerConfig{
+		Processor: modelpb.ProcessBatchFunc(func(ctx context.Context, batch *modelpb.Batch) error {
+			eventSize = (*batch)[0].SizeVT()
+			return nil
+		}),
+		Semaphore:    semaphore.NewWeighted(1),
+		MaxBatchSize: 1,
+	})
+	_, err := consumer.ConsumeTracesWithResult(context.Background(), traces)
+	require.NoError(t, err)
+
+	var batchSizes []int
+	consumer = otlp.NewConsumer(otlp.ConsumerConfig{
+		Processor: modelpb.ProcessBatchFunc(func(ctx context.Context, batch *modelpb.Batch) error {
+			batchSizes = append(batchSizes, len(*batch))
+			return nil
+		}),
+		Semaphore:     semaphore.NewWeighted(1),
+		MaxBatchBytes: eventSize*2 + eventSize/2,
+	})
+	result, err := consumer.ConsumeTracesWithResult(context.Background(), traces)
+	require.NoError(t, err)
+	assert.Equal(t, otlp.ConsumeTracesResult{AcceptedSpans: 9}, result)
+	assert.Equal(t, []int{2, 2, 2, 2, 1}, batchSizes)
+
+	// A batch always holds at least one event, even if it exceeds the limit.
+	batchSizes = nil
+	consumer = otlp.NewConsumer(otlp.ConsumerConfig{
+		Processor: modelpb.ProcessBatchFunc(func(ctx context.Context, batch *modelpb.Batch) error {
+			batchSizes = append(batchSizes, len(*batch))
+			return nil
+		}),
+		Semaphore:     semaphore.NewWeighted(1),
+		MaxBatchBytes: 1,
+	})
+	_, err = consumer.ConsumeTracesWithResult(context.Background(), traces)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 1, 1, 1, 1, 1, 1, 1, 1}, batchSizes)
+}
+
+func TestConsumeTracesMaxBatchSizeProcessError(t *testing.T) {
+	traces := newTracesMultipleScopes(3, 2, 4)
+
+	processErr := errors.New("queue full")
+	var batches int
+	consumer := otlp.NewConsumer(otlp.ConsumerConfig{
+		Processor: modelpb.ProcessBatchFunc(func(ctx context.Context, batch *modelpb.Batch) error {
+			batches++
+			if batches == 2 {
+				return processErr
+			}
+			return nil
+		}),
+		Semaphore:    semaphore.NewWeighted(1),
+		MaxBatchSize: 2,
+	})
+
+	// The remaining events are discarded after the first error, and
+	// the result counts the spans processed before the error.
+	result, err := consumer.ConsumeTracesWithResult(context.Background(), traces)
+	assert.Equal(t, processErr, err)
+	assert.Equal(t, otlp.ConsumeTracesResult{AcceptedSpans: 2}, result)
+	assert.Equal(t, 2, batches)
+	assert.Equal(t, int64(2), consumer.Stats().AcceptedSpans)
+}
+
+func TestConsumeTracesMaxBatchSizeEmpty(t *testing.T) {
+	var batches int
+	consumer := otlp.NewConsumer(otlp.ConsumerConfig{
+		Processor: modelpb.ProcessBatchFunc(func(ctx context.Context, batch *modelpb.Batch) error {
+			batches++
+			assert.Empty(t, *batch)
+			return nil
+		}),
+		Semaphore:    semaphore.NewWeighted(1),
+		MaxBatchSize: 2,
+	})
+	_, err := consumer.ConsumeTracesWithResult(context.Background(), ptrace.NewTraces())
+	require.NoError(t, err)
+	assert.Equal(t, 1, batches)
+}
+
+// newTracesMultipleScopes returns traces with a scope for each element of
+// scopeSpans, holding the specified number of spans. The first two scopes
+// belong to one resource, and the remaining scopes to another. Span IDs
+// are assigned sequentially, starting at 1.
+func newTracesMultipleScopes(scopeSpans ...int) ptrace.Traces {
+	traces := ptrace.NewTraces()
+	var resourceSpans ptrace.ResourceSpans
+	var spanID byte
+	for i, n := range scopeSpans {
+		if i == 0 || i == 2 {
+			resourceSpans = traces.ResourceSpans().AppendEmpty()
+		}
+		spans := resourceSpans.ScopeSpans().AppendEmpty().Spans()
+		for j := 0; j < n; j++ {
+			spanID++
+			span := spans.AppendEmpty()
+			span.SetTraceID(pcommon.TraceID{1})
+			span.SetSpanID(pcommon.SpanID{spanID})
+			span.SetParentSpanID(pcommon.SpanID{0xff})
+		}
+	}
+	return traces
+}
+
 func TestConsumer_JaegerMetadata(t *testing.T) {
 	jaegerBatch := &jaegermodel.Batch{
 		Spans: []*jaegermodel.Span{{
@@ -1744,3 +2493,56 @@ func newUint32(v uint32) *uint32 {
 func newBool(v bool) *bool {
 	return &v
 }
+
+// fakeSemaphore is an input.Semaphore which records its usage. If released
+// is non-nil, a value is sent on it each time the semaphore is released.
+type fakeSemaphore struct {
+	mu        sync.Mutex
+	available int64
+	acquires  int
+	released  chan struct{}
+}
+
+func (s *fakeSemaphore) Acquire(ctx context.Context, n int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.acquires++
+	if s.available < n {
+		return errors.New("fakeSemaphore: Acquire would block")
+	}
+	s.available -= n
+	return nil
+}
+
+func (s *fakeSemaphore) TryAcquire(n int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.available < n {
+		return false
+	}
+	s.available -= n
+	return true
+}
+
+func (s *fakeSemaphore) Release(n int64) {
+	s.mu.Lock()
+	s.available += n
+	s.mu.Unlock()
+	if s.released != nil {
+		s.released <- struct{}{}
+	}
+}
+
+// Available returns the number of tokens available.
+func (s *fakeSemaphore) Available() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.available
+}
+
+// Acquires returns the number of calls to the blocking Acquire method.
+func (s *fakeSemaphore) Acquires() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.acquires
+}
@@ -0,0 +1,109 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package otlp
+
+import (
+	"github.com/elastic/apm-data/model/modelpb"
+)
+
+// metricUnit holds the Elastic unit for a metric, and the factor by
+// which its values must be multiplied to be expressed in that unit.
+type metricUnit struct {
+	unit  string
+	scale float64
+}
+
+// ucumUnits maps OpenTelemetry UCUM units to their Elastic equivalents,
+// without changing the scale of values.
+var ucumUnits = map[string]string{
+	"By":  "byte",
+	"ns":  "nanos",
+	"us":  "micros",
+	"ms":  "ms",
+	"s":   "s",
+	"min": "m",
+	"h":   "h",
+	"d":   "d",
+
+	// Dimensionless units have no Elastic equivalent, and are omitted.
+	// Only well-known annotations are listed: other annotations are
+	// passed through like any other unknown unit.
+	"1":          "",
+	"{count}":    "",
+	"{request}":  "",
+	"{requests}": "",
+}
+
+// normalizedUnits maps OpenTelemetry UCUM units to the Elastic units to
+// which values are converted when normalizing: durations are converted
+// to milliseconds, sizes to bytes, and percentages to ratios.
+var normalizedUnits = map[string]metricUnit{
+	"By":   {unit: "byte", scale: 1},
+	"kBy":  {unit: "byte", scale: 1e3},
+	"MBy":  {unit: "byte", scale: 1e6},
+	"GBy":  {unit: "byte", scale: 1e9},
+	"KiBy": {unit: "byte", scale: 1 << 10},
+	"MiBy": {unit: "byte", scale: 1 << 20},
+	"GiBy": {unit: "byte", scale: 1 << 30},
+	"ns":   {unit: "ms", scale: 1e-6},
+	"us":   {unit: "ms", scale: 1e-3},
+	"ms":   {unit: "ms", scale: 1},
+	"s":    {unit: "ms", scale: 1e3},
+	"min":  {unit: "ms", scale: 60e3},
+	"h":    {unit: "ms", scale: 3600e3},
+	"d":    {unit: "ms", scale: 86400e3},
+	"%":    {unit: "percent", scale: 0.01},
+}
+
+// translateUnit translates an OpenTelemetry UCUM unit to the Elastic
+// model. If normalize is true, values of known units are converted to
+// a common unit for their dimension.
+//
+// Dimensionless units listed in ucumUnits, such as "1" and "{requests}",
+// are omitted, and unknown units are passed through verbatim.
+func translateUnit(ucum string, normalize bool) metricUnit {
+	if normalize {
+		if unit, ok := normalizedUnits[ucum]; ok {
+			return unit
+		}
+	}
+	if unit, ok := ucumUnits[ucum]; ok {
+		return metricUnit{unit: unit, scale: 1}
+	}
+	return metricUnit{unit: ucum, scale: 1}
+}
+
+// apply sets the sample's unit, and scales its values.
+func (u metricUnit) apply(sample *modelpb.MetricsetSample) {
+	sample.Unit = u.unit
+	if u.scale == 1 {
+		return
+	}
+	sample.Value *= u.scale
+	if sample.Histogram != nil {
+		for i := range sample.Histogram.Values {
+			sample.Histogram.Values[i] *= u.scale
+		}
+	}
+	if sample.Summary != nil {
+		sample.Summary.Sum *= u.scale
+	}
+	for _, exemplar := range sample.Exemplars {
+		exemplar.Value *= u.scale
+	}
+}
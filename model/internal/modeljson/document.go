@@ -18,6 +18,8 @@
 package modeljson
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"go.elastic.co/fastjson"
@@ -77,6 +79,19 @@ type Document struct {
 	DocCount            int64     `json:"_doc_count,omitempty"`
 }
 
+// UnmarshalJSON decodes an Elasticsearch document into d.
+//
+// The document may use nested objects, dotted field names, or a mix
+// of both; see normalizeDocument.
+func (d *Document) UnmarshalJSON(data []byte) error {
+	normalized, err := normalizeDocument(data)
+	if err != nil {
+		return err
+	}
+	type document Document // avoid recursing into UnmarshalJSON
+	return json.Unmarshal(normalized, (*document)(d))
+}
+
 type Time time.Time
 
 func (t Time) MarshalFastJSON(w *fastjson.Writer) error {
@@ -86,6 +101,30 @@ func (t Time) MarshalFastJSON(w *fastjson.Writer) error {
 	return nil
 }
 
+// UnmarshalJSON decodes either a date string, or a number of milliseconds
+// since the Unix epoch, as accepted by Elasticsearch's date field type.
+func (t *Time) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return err
+		}
+		*t = Time(parsed.UTC())
+	case float64:
+		*t = Time(time.UnixMilli(int64(v)).UTC())
+	case nil:
+		*t = Time{}
+	default:
+		return fmt.Errorf("invalid timestamp %s", data)
+	}
+	return nil
+}
+
 func (t Time) isZero() bool {
 	return time.Time(t).IsZero()
 }
@@ -17,7 +17,12 @@
 
 package modeljson
 
-import "go.elastic.co/fastjson"
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.elastic.co/fastjson"
+)
 
 type Error struct {
 	Exception   *Exception     `json:"exception,omitempty"`
@@ -139,3 +144,54 @@ func (e *Exception) marshalOne(w *fastjson.Writer, offset, parentOffset int) (in
 	}
 	return nextOffset, nil
 }
+
+// UnmarshalJSON decodes the flattened exception tree produced
+// by MarshalFastJSON, restoring the Cause hierarchy.
+func (e *Exception) UnmarshalJSON(data []byte) error {
+	var flat []struct {
+		Message    string            `json:"message"`
+		Type       string            `json:"type"`
+		Module     string            `json:"module"`
+		Code       string            `json:"code"`
+		Handled    *bool             `json:"handled"`
+		Attributes any               `json:"attributes"`
+		Parent     *int              `json:"parent"`
+		Stacktrace []StacktraceFrame `json:"stacktrace"`
+	}
+	if err := json.Unmarshal(data, &flat); err != nil {
+		return err
+	}
+	*e = Exception{}
+	if len(flat) == 0 {
+		return nil
+	}
+	exceptions := make([]Exception, len(flat))
+	parents := make([]int, len(flat))
+	for i, ex := range flat {
+		exceptions[i] = Exception{
+			Message:    ex.Message,
+			Type:       ex.Type,
+			Module:     ex.Module,
+			Code:       ex.Code,
+			Handled:    ex.Handled,
+			Attributes: ex.Attributes,
+			Stacktrace: ex.Stacktrace,
+		}
+		parents[i] = i - 1
+		if ex.Parent != nil {
+			parents[i] = *ex.Parent
+		}
+		if i > 0 && (parents[i] < 0 || parents[i] >= i) {
+			return fmt.Errorf("invalid parent %d for exception %d", parents[i], i)
+		}
+	}
+	// Exceptions always follow their parents, so by attaching causes
+	// in reverse order each cause is complete before it is copied
+	// into its parent.
+	for i := len(exceptions) - 1; i > 0; i-- {
+		parent := &exceptions[parents[i]]
+		parent.Cause = append([]Exception{exceptions[i]}, parent.Cause...)
+	}
+	*e = exceptions[0]
+	return nil
+}
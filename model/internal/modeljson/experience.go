@@ -17,7 +17,11 @@
 
 package modeljson
 
-import "go.elastic.co/fastjson"
+import (
+	"encoding/json"
+
+	"go.elastic.co/fastjson"
+)
 
 type UserExperience struct {
 	CumulativeLayoutShift float64
@@ -68,3 +72,36 @@ func (u *UserExperience) MarshalFastJSON(w *fastjson.Writer) error {
 	w.RawByte('}')
 	return nil
 }
+
+// UnmarshalJSON decodes u, setting metrics which are missing
+// from the JSON to -1, the inverse of MarshalFastJSON.
+func (u *UserExperience) UnmarshalJSON(data []byte) error {
+	var experience struct {
+		CumulativeLayoutShift *float64         `json:"cls"`
+		FirstInputDelay       *float64         `json:"fid"`
+		TotalBlockingTime     *float64         `json:"tbt"`
+		Longtask              *LongtaskMetrics `json:"longtask"`
+	}
+	if err := json.Unmarshal(data, &experience); err != nil {
+		return err
+	}
+	*u = UserExperience{
+		CumulativeLayoutShift: -1,
+		FirstInputDelay:       -1,
+		TotalBlockingTime:     -1,
+		Longtask:              LongtaskMetrics{Count: -1},
+	}
+	if experience.CumulativeLayoutShift != nil {
+		u.CumulativeLayoutShift = *experience.CumulativeLayoutShift
+	}
+	if experience.FirstInputDelay != nil {
+		u.FirstInputDelay = *experience.FirstInputDelay
+	}
+	if experience.TotalBlockingTime != nil {
+		u.TotalBlockingTime = *experience.TotalBlockingTime
+	}
+	if experience.Longtask != nil {
+		u.Longtask = *experience.Longtask
+	}
+	return nil
+}
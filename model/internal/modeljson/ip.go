@@ -18,6 +18,7 @@
 package modeljson
 
 import (
+	"encoding/json"
 	"net/netip"
 
 	"go.elastic.co/fastjson"
@@ -37,3 +38,16 @@ func (i *IP) MarshalFastJSON(w *fastjson.Writer) error {
 	w.RawByte('"')
 	return nil
 }
+
+func (i *IP) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return err
+	}
+	*i = IP(addr)
+	return nil
+}
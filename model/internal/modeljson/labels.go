@@ -18,6 +18,8 @@
 package modeljson
 
 import (
+	"encoding/json"
+
 	"go.elastic.co/fastjson"
 )
 
@@ -42,6 +44,14 @@ func (v *Label) MarshalFastJSON(w *fastjson.Writer) error {
 	return nil
 }
 
+func (v *Label) UnmarshalJSON(data []byte) error {
+	*v = Label{}
+	if len(data) > 0 && data[0] == '[' {
+		return json.Unmarshal(data, &v.Values)
+	}
+	return json.Unmarshal(data, &v.Value)
+}
+
 type NumericLabel struct {
 	Values []float64
 	Value  float64
@@ -62,3 +72,11 @@ func (v *NumericLabel) MarshalFastJSON(w *fastjson.Writer) error {
 	}
 	return nil
 }
+
+func (v *NumericLabel) UnmarshalJSON(data []byte) error {
+	*v = NumericLabel{}
+	if len(data) > 0 && data[0] == '[' {
+		return json.Unmarshal(data, &v.Values)
+	}
+	return json.Unmarshal(data, &v.Value)
+}
@@ -18,9 +18,10 @@
 package modeljson
 
 type Log struct {
-	Level  string    `json:"level,omitempty"`
-	Logger string    `json:"logger,omitempty"`
-	Origin LogOrigin `json:"origin,omitempty"`
+	Level      string         `json:"level,omitempty"`
+	Logger     string         `json:"logger,omitempty"`
+	Origin     LogOrigin      `json:"origin,omitempty"`
+	Structured map[string]any `json:"structured,omitempty"`
 }
 
 type LogOrigin struct {
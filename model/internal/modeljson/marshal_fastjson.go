@@ -1711,6 +1711,32 @@ func (v *Log) MarshalFastJSON(w *fastjson.Writer) error {
 			firstErr = err
 		}
 	}
+	if v.Structured != nil {
+		const prefix = ",\"structured\":"
+		if first {
+			first = false
+			w.RawString(prefix[1:])
+		} else {
+			w.RawString(prefix)
+		}
+		w.RawByte('{')
+		{
+			first := true
+			for k, v := range v.Structured {
+				if first {
+					first = false
+				} else {
+					w.RawByte(',')
+				}
+				w.String(k)
+				w.RawByte(':')
+				if err := fastjson.Marshal(w, v); err != nil && firstErr == nil {
+					firstErr = err
+				}
+			}
+		}
+		w.RawByte('}')
+	}
 	w.RawByte('}')
 	return firstErr
 }
@@ -2697,6 +2723,18 @@ func (v *Span) MarshalFastJSON(w *fastjson.Writer) error {
 			firstErr = err
 		}
 	}
+	if v.GenAI != nil {
+		const prefix = ",\"gen_ai\":"
+		if first {
+			first = false
+			w.RawString(prefix[1:])
+		} else {
+			w.RawString(prefix)
+		}
+		if err := v.GenAI.MarshalFastJSON(w); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
 	if v.ID != "" {
 		const prefix = ",\"id\":"
 		if first {
@@ -2997,6 +3035,119 @@ func (v *DBUser) MarshalFastJSON(w *fastjson.Writer) error {
 	return nil
 }
 
+func (v *GenAI) MarshalFastJSON(w *fastjson.Writer) error {
+	var firstErr error
+	w.RawByte('{')
+	first := true
+	if !v.Operation.isZero() {
+		const prefix = ",\"operation\":"
+		if first {
+			first = false
+			w.RawString(prefix[1:])
+		} else {
+			w.RawString(prefix)
+		}
+		if err := v.Operation.MarshalFastJSON(w); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if !v.Request.isZero() {
+		const prefix = ",\"request\":"
+		if first {
+			first = false
+			w.RawString(prefix[1:])
+		} else {
+			w.RawString(prefix)
+		}
+		if err := v.Request.MarshalFastJSON(w); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if !v.Response.isZero() {
+		const prefix = ",\"response\":"
+		if first {
+			first = false
+			w.RawString(prefix[1:])
+		} else {
+			w.RawString(prefix)
+		}
+		if err := v.Response.MarshalFastJSON(w); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if v.System != "" {
+		const prefix = ",\"system\":"
+		if first {
+			first = false
+			w.RawString(prefix[1:])
+		} else {
+			w.RawString(prefix)
+		}
+		w.String(v.System)
+	}
+	if !v.Usage.isZero() {
+		const prefix = ",\"usage\":"
+		if first {
+			first = false
+			w.RawString(prefix[1:])
+		} else {
+			w.RawString(prefix)
+		}
+		if err := v.Usage.MarshalFastJSON(w); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	w.RawByte('}')
+	return firstErr
+}
+
+func (v *GenAIOperation) MarshalFastJSON(w *fastjson.Writer) error {
+	w.RawByte('{')
+	if v.Name != "" {
+		w.RawString("\"name\":")
+		w.String(v.Name)
+	}
+	w.RawByte('}')
+	return nil
+}
+
+func (v *GenAIModel) MarshalFastJSON(w *fastjson.Writer) error {
+	w.RawByte('{')
+	if v.Model != "" {
+		w.RawString("\"model\":")
+		w.String(v.Model)
+	}
+	w.RawByte('}')
+	return nil
+}
+
+func (v *GenAIUsage) MarshalFastJSON(w *fastjson.Writer) error {
+	w.RawByte('{')
+	first := true
+	if v.InputTokens != nil {
+		const prefix = ",\"input_tokens\":"
+		if first {
+			first = false
+			w.RawString(prefix[1:])
+		} else {
+			w.RawString(prefix)
+		}
+		w.Uint64(*v.InputTokens)
+	}
+	if v.OutputTokens != nil {
+		const prefix = ",\"output_tokens\":"
+		if first {
+			first = false
+			w.RawString(prefix[1:])
+		} else {
+			w.RawString(prefix)
+		}
+		w.Uint64(*v.OutputTokens)
+	}
+	w.RawByte('}')
+	return nil
+}
+
 func (v *SpanLink) MarshalFastJSON(w *fastjson.Writer) error {
 	var firstErr error
 	w.RawByte('{')
@@ -18,6 +18,7 @@
 package modeljson
 
 import (
+	"encoding/json"
 	"time"
 
 	"go.elastic.co/fastjson"
@@ -30,12 +31,25 @@ type Metricset struct {
 }
 
 type MetricsetSample struct {
-	Name      string
-	Type      string
-	Unit      string
-	Histogram Histogram
-	Summary   SummaryMetric
-	Value     float64
+	Name        string
+	Type        string
+	Unit        string
+	Temporality string
+	Histogram   Histogram
+	Summary     SummaryMetric
+	Value       float64
+	Exemplars   []Exemplar
+}
+
+// Exemplar holds an example measurement recorded for a metric sample,
+// along with the trace and span in which it was recorded.
+type Exemplar struct {
+	Timestamp     Time
+	Value         float64
+	TraceID       string
+	SpanID        string
+	Labels        map[string]Label
+	NumericLabels map[string]NumericLabel
 }
 
 type Histogram struct {
@@ -69,6 +83,10 @@ func (ms *MetricsetSample) MarshalFastJSON(w *fastjson.Writer) error {
 		w.RawString(`,"unit":`)
 		w.String(ms.Unit)
 	}
+	if ms.Temporality != "" {
+		w.RawString(`,"temporality":`)
+		w.String(ms.Temporality)
+	}
 	switch ms.Type {
 	case "histogram":
 		w.RawString(`,"values":[`)
@@ -95,10 +113,136 @@ func (ms *MetricsetSample) MarshalFastJSON(w *fastjson.Writer) error {
 		w.RawString(`,"value":`)
 		w.Float64(ms.Value)
 	}
+	if len(ms.Exemplars) > 0 {
+		w.RawString(`,"exemplars":[`)
+		for i := range ms.Exemplars {
+			if i > 0 {
+				w.RawByte(',')
+			}
+			if err := ms.Exemplars[i].MarshalFastJSON(w); err != nil {
+				return err
+			}
+		}
+		w.RawByte(']')
+	}
 	w.RawByte('}')
 	return nil
 }
 
+func (ms *MetricsetSample) UnmarshalJSON(data []byte) error {
+	var sample struct {
+		Name        string     `json:"name"`
+		Type        string     `json:"type"`
+		Unit        string     `json:"unit"`
+		Temporality string     `json:"temporality"`
+		Values      []float64  `json:"values"`
+		Counts      []int64    `json:"counts"`
+		Count       int64      `json:"value_count"`
+		Sum         float64    `json:"sum"`
+		Value       float64    `json:"value"`
+		Exemplars   []Exemplar `json:"exemplars"`
+	}
+	if err := json.Unmarshal(data, &sample); err != nil {
+		return err
+	}
+	*ms = MetricsetSample{
+		Name:        sample.Name,
+		Type:        sample.Type,
+		Unit:        sample.Unit,
+		Temporality: sample.Temporality,
+		Exemplars:   sample.Exemplars,
+	}
+	switch sample.Type {
+	case "histogram":
+		ms.Histogram = Histogram{Values: sample.Values, Counts: sample.Counts}
+	case "summary":
+		ms.Summary = SummaryMetric{Count: sample.Count, Sum: sample.Sum}
+	default:
+		ms.Value = sample.Value
+	}
+	return nil
+}
+
+func (e *Exemplar) MarshalFastJSON(w *fastjson.Writer) error {
+	w.RawString(`{"@timestamp":`)
+	if err := e.Timestamp.MarshalFastJSON(w); err != nil {
+		return err
+	}
+	w.RawString(`,"value":`)
+	w.Float64(e.Value)
+	if e.TraceID != "" {
+		w.RawString(`,"trace":{"id":`)
+		w.String(e.TraceID)
+		w.RawByte('}')
+	}
+	if e.SpanID != "" {
+		w.RawString(`,"span":{"id":`)
+		w.String(e.SpanID)
+		w.RawByte('}')
+	}
+	if len(e.Labels) > 0 {
+		w.RawString(`,"labels":{`)
+		first := true
+		for k, v := range e.Labels {
+			if !first {
+				w.RawByte(',')
+			}
+			first = false
+			w.String(k)
+			w.RawByte(':')
+			if err := v.MarshalFastJSON(w); err != nil {
+				return err
+			}
+		}
+		w.RawByte('}')
+	}
+	if len(e.NumericLabels) > 0 {
+		w.RawString(`,"numeric_labels":{`)
+		first := true
+		for k, v := range e.NumericLabels {
+			if !first {
+				w.RawByte(',')
+			}
+			first = false
+			w.String(k)
+			w.RawByte(':')
+			if err := v.MarshalFastJSON(w); err != nil {
+				return err
+			}
+		}
+		w.RawByte('}')
+	}
+	w.RawByte('}')
+	return nil
+}
+
+func (e *Exemplar) UnmarshalJSON(data []byte) error {
+	var exemplar struct {
+		Timestamp Time    `json:"@timestamp"`
+		Value     float64 `json:"value"`
+		Trace     struct {
+			ID string `json:"id"`
+		} `json:"trace"`
+		Span struct {
+			ID string `json:"id"`
+		} `json:"span"`
+		Labels        map[string]Label        `json:"labels"`
+		NumericLabels map[string]NumericLabel `json:"numeric_labels"`
+	}
+	if err := json.Unmarshal(data, &exemplar); err != nil {
+		return err
+	}
+	*e = Exemplar{
+		Timestamp:     exemplar.Timestamp,
+		Value:         exemplar.Value,
+		TraceID:       exemplar.Trace.ID,
+		SpanID:        exemplar.Span.ID,
+		Labels:        exemplar.Labels,
+		NumericLabels: exemplar.NumericLabels,
+	}
+	return nil
+}
+
 type AggregatedDuration struct {
 	Count int
 	Sum   time.Duration
@@ -116,3 +260,18 @@ func (d AggregatedDuration) MarshalFastJSON(w *fastjson.Writer) error {
 	w.RawByte('}')
 	return nil
 }
+
+func (d *AggregatedDuration) UnmarshalJSON(data []byte) error {
+	var duration struct {
+		Count int   `json:"count"`
+		SumUS int64 `json:"sum.us"`
+	}
+	if err := json.Unmarshal(data, &duration); err != nil {
+		return err
+	}
+	*d = AggregatedDuration{
+		Count: duration.Count,
+		Sum:   time.Duration(duration.SumUS) * time.Microsecond,
+	}
+	return nil
+}
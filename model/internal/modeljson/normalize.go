@@ -0,0 +1,175 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package modeljson
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// opaqueFields holds the paths of fields whose values are free-form
+// objects. Keys within these objects are never split on dots.
+//
+// Paths do not include array indices, so e.g. "span.stacktrace.vars"
+// matches the vars of every span stack frame.
+var opaqueFields = map[string]bool{
+	"error.custom":                    true,
+	"error.exception.attributes":      true,
+	"error.exception.stacktrace.vars": true,
+	"error.log.stacktrace.vars":       true,
+	"http.request.body.original":      true,
+	"http.request.cookies":            true,
+	"http.request.env":                true,
+	"http.request.headers":            true,
+	"http.response.headers":           true,
+	"log.structured":                  true,
+	"span.message.headers":            true,
+	"span.stacktrace.vars":            true,
+	"transaction.custom":              true,
+	"transaction.message.headers":     true,
+}
+
+// dottedFields holds, for a given object path, the names of child objects
+// which Document encodes using dotted field names rather than nesting.
+var dottedFields = map[string][]string{
+	"":            {"data_stream"},
+	"transaction": {"duration"},
+	"transaction.dropped_spans_stats.duration": {"sum"},
+	"span.self_time":                         {"sum"},
+	"span.destination.service.response_time": {"sum"},
+}
+
+// normalizeDocument rewrites an Elasticsearch document into the form
+// produced by Document.MarshalFastJSON.
+//
+// Documents read back from Elasticsearch may have been indexed with
+// nested objects (`{"data_stream": {"type": "logs"}}`), dotted field
+// names (`{"data_stream.type": "logs"}`), or a mix of the two. All
+// fields are first expanded into nested objects, and then the fields
+// listed in dottedFields are collapsed back into dotted field names.
+func normalizeDocument(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber() // avoid losing precision in round trip
+	var m map[string]any
+	if err := dec.Decode(&m); err != nil {
+		return nil, err
+	}
+	m = expandObject("", m)
+	collapseValue("", m)
+	return json.Marshal(m)
+}
+
+// expandObject returns a copy of in, with dotted field names
+// expanded into nested objects.
+func expandObject(path string, in map[string]any) map[string]any {
+	out := make(map[string]any, len(in))
+	for k, v := range in {
+		obj, objPath := out, path
+		parts := strings.Split(k, ".")
+		for i, part := range parts[:len(parts)-1] {
+			child, ok := obj[part].(map[string]any)
+			if !ok {
+				child = make(map[string]any)
+				obj[part] = child
+			}
+			obj, objPath = child, joinPath(objPath, part)
+			if opaqueFields[objPath] {
+				// Everything after an opaque field is a literal key.
+				parts = []string{strings.Join(parts[i+1:], ".")}
+				break
+			}
+		}
+		key := parts[len(parts)-1]
+		if opaqueFields[objPath] {
+			obj[key] = v
+			continue
+		}
+		v = expandValue(joinPath(objPath, key), v)
+		if existing, ok := obj[key].(map[string]any); ok {
+			if vm, ok := v.(map[string]any); ok {
+				mergeObjects(existing, vm)
+				continue
+			}
+		}
+		obj[key] = v
+	}
+	return out
+}
+
+func expandValue(path string, v any) any {
+	if opaqueFields[path] {
+		return v
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		return expandObject(path, v)
+	case []any:
+		for i, elem := range v {
+			v[i] = expandValue(path, elem)
+		}
+	}
+	return v
+}
+
+// collapseValue replaces the objects listed in dottedFields with
+// dotted field names, in place.
+func collapseValue(path string, v any) {
+	if opaqueFields[path] {
+		return
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			collapseValue(joinPath(path, k), child)
+		}
+		for _, field := range dottedFields[path] {
+			if child, ok := v[field].(map[string]any); ok {
+				delete(v, field)
+				for k, childValue := range child {
+					v[field+"."+k] = childValue
+				}
+			}
+		}
+	case []any:
+		for _, elem := range v {
+			collapseValue(path, elem)
+		}
+	}
+}
+
+// mergeObjects merges src into dst, where both have already been
+// expanded. Scalar values in src take precedence.
+func mergeObjects(dst, src map[string]any) {
+	for k, v := range src {
+		if existing, ok := dst[k].(map[string]any); ok {
+			if vm, ok := v.(map[string]any); ok {
+				mergeObjects(existing, vm)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
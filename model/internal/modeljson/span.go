@@ -33,6 +33,7 @@ type Span struct {
 	Links               []SpanLink         `json:"links,omitempty"`
 	SelfTime            AggregatedDuration `json:"self_time,omitempty"`
 	RepresentativeCount float64            `json:"representative_count,omitempty"`
+	GenAI               *GenAI             `json:"gen_ai,omitempty"`
 }
 
 type SpanDestination struct {
@@ -72,3 +73,36 @@ type DBUser struct {
 func (d DBUser) isZero() bool {
 	return d == DBUser{}
 }
+
+type GenAI struct {
+	System    string         `json:"system,omitempty"`
+	Operation GenAIOperation `json:"operation,omitempty"`
+	Request   GenAIModel     `json:"request,omitempty"`
+	Response  GenAIModel     `json:"response,omitempty"`
+	Usage     GenAIUsage     `json:"usage,omitempty"`
+}
+
+type GenAIOperation struct {
+	Name string `json:"name,omitempty"`
+}
+
+func (o GenAIOperation) isZero() bool {
+	return o == GenAIOperation{}
+}
+
+type GenAIModel struct {
+	Model string `json:"model,omitempty"`
+}
+
+func (m GenAIModel) isZero() bool {
+	return m == GenAIModel{}
+}
+
+type GenAIUsage struct {
+	InputTokens  *uint64 `json:"input_tokens,omitempty"`
+	OutputTokens *uint64 `json:"output_tokens,omitempty"`
+}
+
+func (u GenAIUsage) isZero() bool {
+	return u == GenAIUsage{}
+}
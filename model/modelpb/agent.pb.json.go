@@ -27,3 +27,12 @@ func (a *Agent) toModelJSON(out *modeljson.Agent) {
 		ActivationMethod: a.ActivationMethod,
 	}
 }
+
+func (a *Agent) fromModelJSON(in *modeljson.Agent) {
+	*a = Agent{
+		Name:             in.Name,
+		Version:          in.Version,
+		EphemeralId:      in.EphemeralID,
+		ActivationMethod: in.ActivationMethod,
+	}
+}
@@ -18,8 +18,12 @@
 package modelpb
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/elastic/apm-data/model/internal/modeljson"
 	"go.elastic.co/fastjson"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (e *APMEvent) MarshalJSON() ([]byte, error) {
@@ -262,3 +266,215 @@ func (e *APMEvent) MarshalFastJSON(w *fastjson.Writer) error {
 
 	return doc.MarshalFastJSON(w)
 }
+
+// UnmarshalJSON decodes an Elasticsearch document, such as one produced
+// by MarshalJSON, into e. The document may use nested objects, dotted
+// field names (e.g. "data_stream.type"), or a mix of both.
+//
+// Decoding is the inverse of MarshalJSON only for the fields which are
+// serialized: for example, the Global property of labels is never
+// serialized, and @timestamp has millisecond precision for events other
+// than transactions, spans and errors.
+func (e *APMEvent) UnmarshalJSON(data []byte) error {
+	var doc modeljson.Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	e.fromModelJSON(&doc)
+	return nil
+}
+
+func (e *APMEvent) fromModelJSON(doc *modeljson.Document) {
+	*e = APMEvent{
+		Timestamp: timestamppb.New(time.Time(doc.Timestamp)),
+		Message:   doc.Message,
+	}
+
+	if n := len(doc.Labels); n > 0 {
+		e.Labels = make(map[string]*LabelValue, n)
+		for k, label := range doc.Labels {
+			e.Labels[k] = &LabelValue{
+				Value:  label.Value,
+				Values: label.Values,
+			}
+		}
+	}
+
+	if n := len(doc.NumericLabels); n > 0 {
+		e.NumericLabels = make(map[string]*NumericLabelValue, n)
+		for k, label := range doc.NumericLabels {
+			e.NumericLabels[k] = &NumericLabelValue{
+				Value:  label.Value,
+				Values: label.Values,
+			}
+		}
+	}
+
+	if doc.DataStreamType != "" || doc.DataStreamDataset != "" || doc.DataStreamNamespace != "" {
+		e.DataStream = &DataStream{
+			Type:      doc.DataStreamType,
+			Dataset:   doc.DataStreamDataset,
+			Namespace: doc.DataStreamNamespace,
+		}
+	}
+
+	if doc.Processor != (modeljson.Processor{}) {
+		e.Processor = &Processor{
+			Name:  doc.Processor.Name,
+			Event: doc.Processor.Event,
+		}
+	}
+
+	// The high resolution timestamp is only recorded for
+	// transactions, spans and errors; see MarshalFastJSON.
+	if doc.TimestampStruct != nil {
+		switch {
+		case e.Processor.IsTransaction(), e.Processor.IsSpan(), e.Processor.IsError():
+			e.Timestamp = timestamppb.New(time.UnixMicro(int64(doc.TimestampStruct.US)))
+		}
+	}
+
+	if doc.Transaction != nil {
+		e.Transaction = &Transaction{}
+		e.Transaction.fromModelJSON(doc.Transaction)
+	}
+
+	if doc.Span != nil {
+		e.Span = &Span{}
+		e.Span.fromModelJSON(doc.Span)
+	}
+
+	if doc.Metricset != nil {
+		e.Metricset = &Metricset{}
+		e.Metricset.fromModelJSON(doc.Metricset)
+		e.Metricset.DocCount = doc.DocCount
+	}
+
+	if doc.Error != nil {
+		e.Error = &Error{}
+		e.Error.fromModelJSON(doc.Error)
+	}
+
+	if doc.Event != nil {
+		e.Event = &Event{}
+		e.Event.fromModelJSON(doc.Event)
+	}
+
+	if doc.Cloud != nil {
+		e.Cloud = &Cloud{}
+		e.Cloud.fromModelJSON(doc.Cloud)
+	}
+
+	if doc.FAAS != nil {
+		e.Faas = &Faas{}
+		e.Faas.fromModelJSON(doc.FAAS)
+	}
+
+	if doc.Device != nil {
+		e.Device = &Device{}
+		e.Device.fromModelJSON(doc.Device)
+	}
+
+	if doc.Network != nil {
+		e.Network = &Network{}
+		e.Network.fromModelJSON(doc.Network)
+	}
+
+	if doc.Observer != nil {
+		e.Observer = &Observer{}
+		e.Observer.fromModelJSON(doc.Observer)
+	}
+
+	if doc.Container != nil {
+		e.Container = &Container{}
+		e.Container.fromModelJSON(doc.Container)
+	}
+
+	if doc.Kubernetes != nil {
+		e.Kubernetes = &Kubernetes{}
+		e.Kubernetes.fromModelJSON(doc.Kubernetes)
+	}
+
+	if doc.Agent != nil {
+		e.Agent = &Agent{}
+		e.Agent.fromModelJSON(doc.Agent)
+	}
+
+	if doc.Trace != nil {
+		e.Trace = &Trace{
+			Id: doc.Trace.ID,
+		}
+	}
+
+	if doc.User != nil {
+		e.User = &User{}
+		e.User.fromModelJSON(doc.User)
+	}
+
+	if doc.Source != nil {
+		e.Source = &Source{}
+		e.Source.fromModelJSON(doc.Source)
+	}
+
+	if doc.Parent != nil {
+		e.ParentId = doc.Parent.ID
+	}
+
+	if doc.Child != nil {
+		e.ChildIds = doc.Child.ID
+	}
+
+	if doc.Client != nil {
+		e.Client = &Client{}
+		e.Client.fromModelJSON(doc.Client)
+	}
+
+	if doc.UserAgent != nil {
+		e.UserAgent = &UserAgent{
+			Original: doc.UserAgent.Original,
+			Name:     doc.UserAgent.Name,
+		}
+	}
+
+	if doc.Service != nil {
+		e.Service = &Service{}
+		e.Service.fromModelJSON(doc.Service)
+	}
+
+	if doc.HTTP != nil {
+		e.Http = &HTTP{}
+		e.Http.fromModelJSON(doc.HTTP)
+	}
+
+	if doc.Host != nil {
+		e.Host = &Host{}
+		e.Host.fromModelJSON(doc.Host)
+	}
+
+	if doc.URL != nil {
+		e.Url = &URL{}
+		e.Url.fromModelJSON(doc.URL)
+	}
+
+	if doc.Log != nil {
+		e.Log = &Log{}
+		e.Log.fromModelJSON(doc.Log)
+	}
+
+	if doc.Process != nil {
+		e.Process = &Process{}
+		e.Process.fromModelJSON(doc.Process)
+	}
+
+	if doc.Destination != nil {
+		e.Destination = &Destination{}
+		e.Destination.fromModelJSON(doc.Destination)
+	}
+
+	if doc.Session != nil {
+		e.Session = &Session{
+			Id:       doc.Session.ID,
+			Sequence: int64(doc.Session.Sequence),
+		}
+	}
+}
@@ -18,13 +18,212 @@
 package modelpb
 
 import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/rand"
+	"sort"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/testing/protocmp"
 	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// seed holds the seed for randomized tests. It is fixed by default so
+// that failures are reproducible; vary it with e.g. -seed=$RANDOM.
+var seed = flag.Int64("seed", 1, "seed for randomized tests")
+
+func TestAPMEventJSONRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(*seed))
+	for i := 0; i < 100; i++ {
+		event := randomSerializableEvent(r)
+		data, err := event.MarshalJSON()
+		require.NoError(t, err)
+
+		var decoded APMEvent
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		if diff := cmp.Diff(event, &decoded, protocmp.Transform()); diff != "" {
+			t.Fatalf("round trip mismatch (seed %d, iteration %d):\n%s\n%s", *seed, i, data, diff)
+		}
+	}
+}
+
+func TestAPMEventJSONRoundTripLogStructured(t *testing.T) {
+	structured, err := structpb.NewStruct(map[string]any{
+		"a": map[string]any{"b.c": 1},
+		"d": []any{map[string]any{"e.f": true}},
+	})
+	require.NoError(t, err)
+	event := &APMEvent{
+		Timestamp: timestamppb.New(time.Unix(123, 0)),
+		Processor: LogProcessor(),
+		Log:       &Log{Structured: structured},
+	}
+	data, err := event.MarshalJSON()
+	require.NoError(t, err)
+
+	// Keys within structured log bodies must not be expanded.
+	var decoded APMEvent
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Empty(t, cmp.Diff(event, &decoded, protocmp.Transform()))
+}
+
+func TestAPMEventUnmarshalJSONDotted(t *testing.T) {
+	expected := &APMEvent{
+		Timestamp: timestamppb.New(time.Date(2023, 5, 1, 12, 30, 0, 123000000, time.UTC)),
+		Processor: MetricsetProcessor(),
+		DataStream: &DataStream{
+			Type:      "metrics",
+			Dataset:   "apm.internal",
+			Namespace: "default",
+		},
+		Service: &Service{
+			Name:     "svc",
+			Language: &Language{Name: "go"},
+		},
+		Labels: map[string]*LabelValue{
+			"a": {Value: "b"},
+			"c": {Values: []string{"d", "e"}},
+		},
+		NumericLabels: map[string]*NumericLabelValue{
+			"f": {Value: 1.5},
+			"g": {Values: []float64{1, 2}},
+		},
+		Transaction: &Transaction{
+			Name: "GET /",
+			DurationHistogram: &Histogram{
+				Values: []float64{1, 2},
+				Counts: []int64{3, 4},
+			},
+			DurationSummary: &SummaryMetric{Count: 7, Sum: 10},
+		},
+		Metricset: &Metricset{
+			Name:     "transaction",
+			DocCount: 7,
+			Samples: []*MetricsetSample{{
+				Type: MetricType_METRIC_TYPE_HISTOGRAM,
+				Name: "latency",
+				Histogram: &Histogram{
+					Values: []float64{1},
+					Counts: []int64{7},
+				},
+			}, {
+				Type:  MetricType_METRIC_TYPE_GAUGE,
+				Name:  "gauge",
+				Value: 0.5,
+			}},
+		},
+		Http: &HTTP{
+			Request: &HTTPRequest{
+				Headers: []*HTTPHeader{{Key: "X.Dotted", Value: []string{"value"}}},
+			},
+		},
+	}
+
+	for name, doc := range map[string]string{
+		"nested": `{
+			"@timestamp": "2023-05-01T12:30:00.123Z",
+			"processor": {"name": "metric", "event": "metric"},
+			"data_stream": {"type": "metrics", "dataset": "apm.internal", "namespace": "default"},
+			"service": {"name": "svc", "language": {"name": "go"}},
+			"labels": {"a": "b", "c": ["d", "e"]},
+			"numeric_labels": {"f": 1.5, "g": [1, 2]},
+			"transaction": {
+				"name": "GET /",
+				"duration": {
+					"histogram": {"values": [1, 2], "counts": [3, 4]},
+					"summary": {"value_count": 7, "sum": 10}
+				}
+			},
+			"metricset": {"name": "transaction", "samples": [
+				{"name": "latency", "type": "histogram", "values": [1], "counts": [7]},
+				{"name": "gauge", "type": "gauge", "value": 0.5}
+			]},
+			"http": {"request": {"headers": {"X.Dotted": ["value"]}}},
+			"_doc_count": 7
+		}`,
+		"dotted": `{
+			"@timestamp": "2023-05-01T12:30:00.123Z",
+			"processor.name": "metric",
+			"processor.event": "metric",
+			"data_stream.type": "metrics",
+			"data_stream.dataset": "apm.internal",
+			"data_stream.namespace": "default",
+			"service.name": "svc",
+			"service.language.name": "go",
+			"labels.a": "b",
+			"labels.c": ["d", "e"],
+			"numeric_labels.f": 1.5,
+			"numeric_labels.g": [1, 2],
+			"transaction.name": "GET /",
+			"transaction.duration.histogram": {"values": [1, 2], "counts": [3, 4]},
+			"transaction.duration.summary.value_count": 7,
+			"transaction.duration.summary.sum": 10,
+			"metricset.name": "transaction",
+			"metricset.samples": [
+				{"name": "latency", "type": "histogram", "values": [1], "counts": [7]},
+				{"name": "gauge", "type": "gauge", "value": 0.5}
+			],
+			"http.request.headers.X.Dotted": ["value"],
+			"_doc_count": 7
+		}`,
+		"mixed": `{
+			"@timestamp": "2023-05-01T12:30:00.123Z",
+			"processor": {"name": "metric"},
+			"processor.event": "metric",
+			"data_stream": {"type": "metrics"},
+			"data_stream.dataset": "apm.internal",
+			"data_stream.namespace": "default",
+			"service": {"name": "svc", "language.name": "go"},
+			"labels": {"a": "b"},
+			"labels.c": ["d", "e"],
+			"numeric_labels": {"f": 1.5, "g": [1, 2]},
+			"transaction": {"name": "GET /", "duration.summary": {"value_count": 7, "sum": 10}},
+			"transaction.duration": {"histogram": {"values": [1, 2], "counts": [3, 4]}},
+			"metricset": {"name": "transaction", "samples": [
+				{"name": "latency", "type": "histogram", "values": [1], "counts": [7]},
+				{"name": "gauge", "type": "gauge", "value": 0.5}
+			]},
+			"http.request": {"headers": {"X.Dotted": ["value"]}},
+			"_doc_count": 7
+		}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var event APMEvent
+			require.NoError(t, json.Unmarshal([]byte(doc), &event))
+			assert.Empty(t, cmp.Diff(expected, &event, protocmp.Transform()))
+		})
+	}
+}
+
+func TestAPMEventUnmarshalJSONTimestamp(t *testing.T) {
+	var event APMEvent
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"@timestamp": "2023-05-01T12:30:00.123Z",
+		"timestamp": {"us": 1682944200123456},
+		"processor": {"name": "transaction", "event": "span"}
+	}`), &event))
+	assert.Equal(t, time.Date(2023, 5, 1, 12, 30, 0, 123456000, time.UTC), event.Timestamp.AsTime())
+
+	// timestamp.us is only used for transactions, spans and errors.
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"@timestamp": "2023-05-01T12:30:00.123Z",
+		"timestamp": {"us": 1682944200123456},
+		"processor": {"name": "log", "event": "log"}
+	}`), &event))
+	assert.Equal(t, time.Date(2023, 5, 1, 12, 30, 0, 123000000, time.UTC), event.Timestamp.AsTime())
+
+	// Epoch milliseconds are also accepted.
+	require.NoError(t, json.Unmarshal([]byte(`{"@timestamp": 1682944200123}`), &event))
+	assert.Equal(t, time.Date(2023, 5, 1, 12, 30, 0, 123000000, time.UTC), event.Timestamp.AsTime())
+}
+
 func BenchmarkAPMEventToJSON(b *testing.B) {
 	event := fullEvent(b)
 
@@ -481,3 +680,409 @@ func fullEvent(t testing.TB) *APMEvent {
 		},
 	}
 }
+
+// randomSerializableEvent returns a random event which is restricted to
+// the fields, and the precision, that MarshalJSON serializes.
+func randomSerializableEvent(r *rand.Rand) *APMEvent {
+	str := func() string {
+		b := make([]byte, 1+r.Intn(8))
+		for i := range b {
+			b[i] = letters[r.Intn(len(letters))]
+		}
+		return string(b)
+	}
+	strs := func() []string {
+		out := make([]string, 1+r.Intn(3))
+		for i := range out {
+			out[i] = str()
+		}
+		return out
+	}
+	maybe := func() bool { return r.Intn(2) == 0 }
+	u32 := func() *uint32 { v := r.Uint32(); return &v }
+	i64 := func() *int64 { v := r.Int63(); return &v }
+	u64 := func() *uint64 { v := uint64(r.Int63n(1e15)); return &v }
+	b := func() *bool { v := maybe(); return &v }
+	ip := func() string {
+		return fmt.Sprintf("10.%d.%d.%d", r.Intn(256), r.Intn(256), r.Intn(256))
+	}
+	// Times are encoded with millisecond precision, durations with microsecond precision.
+	ms := func() time.Time { return time.UnixMilli(r.Int63n(7e12)).UTC() } // before 2262, see UnixNano
+	us := func() time.Duration { return time.Duration(1+r.Int63n(1e12)) * time.Microsecond }
+	st := func() *structpb.Struct {
+		m := map[string]any{str(): str(), str(): float64(r.Intn(100)), str(): maybe()}
+		s, err := structpb.NewStruct(m)
+		if err != nil {
+			panic(err)
+		}
+		return s
+	}
+	headers := func() []*HTTPHeader {
+		keys := strs()
+		sort.Strings(keys)
+		var out []*HTTPHeader
+		for i, k := range keys {
+			if i > 0 && keys[i-1] == k {
+				continue
+			}
+			out = append(out, &HTTPHeader{Key: k, Value: strs()})
+		}
+		return out
+	}
+	aggregatedDuration := func() *AggregatedDuration {
+		return &AggregatedDuration{Count: 1 + r.Int63n(1000), Sum: durationpb.New(us())}
+	}
+	histogram := func() *Histogram {
+		return &Histogram{Values: []float64{r.Float64(), r.Float64()}, Counts: []int64{r.Int63(), r.Int63()}}
+	}
+	summary := func() *SummaryMetric {
+		return &SummaryMetric{Count: 1 + r.Int63n(1000), Sum: r.Float64()}
+	}
+	message := func() *Message {
+		return &Message{Body: str(), Headers: headers(), AgeMillis: i64(), QueueName: str(), RoutingKey: str()}
+	}
+	frames := func() []*StacktraceFrame {
+		out := make([]*StacktraceFrame, 1+r.Intn(3))
+		for i := range out {
+			frame := &StacktraceFrame{
+				Vars:                st(),
+				Lineno:              u32(),
+				Colno:               u32(),
+				Filename:            str(),
+				Classname:           str(),
+				ContextLine:         str(),
+				Module:              str(),
+				Function:            str(),
+				AbsPath:             str(),
+				SourcemapError:      str(),
+				PreContext:          strs(),
+				PostContext:         strs(),
+				LibraryFrame:        maybe(),
+				SourcemapUpdated:    maybe(),
+				ExcludeFromGrouping: maybe(),
+			}
+			if frame.SourcemapUpdated {
+				frame.Original = &Original{
+					AbsPath:      str(),
+					Filename:     str(),
+					Classname:    str(),
+					Lineno:       u32(),
+					Colno:        u32(),
+					Function:     str(),
+					LibraryFrame: maybe(),
+				}
+			} else if maybe() {
+				frame.Original = &Original{LibraryFrame: true}
+			}
+			out[i] = frame
+		}
+		return out
+	}
+	var exception func(depth int) *Exception
+	exception = func(depth int) *Exception {
+		ex := &Exception{
+			Message:    str(),
+			Module:     str(),
+			Code:       str(),
+			Attributes: st(),
+			Stacktrace: frames(),
+			Type:       str(),
+			Handled:    b(),
+		}
+		if depth < 3 {
+			for i := r.Intn(3); i > 0; i-- {
+				ex.Cause = append(ex.Cause, exception(depth+1))
+			}
+		}
+		return ex
+	}
+
+	processors := []*Processor{
+		TransactionProcessor(), SpanProcessor(), ErrorProcessor(), LogProcessor(), MetricsetProcessor(),
+	}
+	e := &APMEvent{
+		Processor: processors[r.Intn(len(processors))],
+		Timestamp: timestamppb.New(ms()),
+		Message:   str(),
+		ParentId:  str(),
+		ChildIds:  strs(),
+		Trace:     &Trace{Id: str()},
+		DataStream: &DataStream{
+			Type:      str(),
+			Dataset:   str(),
+			Namespace: str(),
+		},
+		Labels: map[string]*LabelValue{
+			str(): {Value: str()},
+			str(): {Values: strs()},
+		},
+		NumericLabels: map[string]*NumericLabelValue{
+			str(): {Value: r.Float64()},
+			str(): {Values: []float64{r.Float64(), r.Float64()}},
+		},
+		Cloud: &Cloud{
+			Origin: &CloudOrigin{
+				AccountId:   str(),
+				Provider:    str(),
+				Region:      str(),
+				ServiceName: str(),
+			},
+			AccountId:        str(),
+			AccountName:      str(),
+			AvailabilityZone: str(),
+			InstanceId:       str(),
+			InstanceName:     str(),
+			MachineType:      str(),
+			ProjectId:        str(),
+			ProjectName:      str(),
+			Provider:         str(),
+			Region:           str(),
+			ServiceName:      str(),
+		},
+		Service: &Service{
+			Origin:      &ServiceOrigin{Id: str(), Name: str(), Version: str()},
+			Target:      &ServiceTarget{Name: str(), Type: str()},
+			Language:    &Language{Name: str(), Version: str()},
+			Runtime:     &Runtime{Name: str(), Version: str()},
+			Framework:   &Framework{Name: str(), Version: str()},
+			Node:        &ServiceNode{Name: str()},
+			Name:        str(),
+			Version:     str(),
+			Environment: str(),
+		},
+		Faas: &Faas{
+			Id:               str(),
+			ColdStart:        b(),
+			Execution:        str(),
+			TriggerType:      str(),
+			TriggerRequestId: str(),
+			Name:             str(),
+			Version:          str(),
+		},
+		Network: &Network{
+			Connection: &NetworkConnection{Type: str(), Subtype: str()},
+			Carrier:    &NetworkCarrier{Name: str(), Mcc: str(), Mnc: str(), Icc: str()},
+		},
+		Container: &Container{Id: str(), Name: str(), Runtime: str(), ImageName: str(), ImageTag: str()},
+		User:      &User{Domain: str(), Id: str(), Email: str(), Name: str()},
+		Device: &Device{
+			Id:           str(),
+			Model:        &DeviceModel{Name: str(), Identifier: str()},
+			Manufacturer: str(),
+		},
+		Kubernetes: &Kubernetes{Namespace: str(), NodeName: str(), PodName: str(), PodUid: str()},
+		Observer:   &Observer{Hostname: str(), Name: str(), Type: str(), Version: str()},
+		Agent:      &Agent{Name: str(), Version: str(), EphemeralId: str(), ActivationMethod: str()},
+		Http: &HTTP{
+			Request: &HTTPRequest{
+				Headers:  headers(),
+				Env:      st(),
+				Cookies:  st(),
+				Body:     structpb.NewStringValue(str()),
+				Id:       str(),
+				Method:   str(),
+				Referrer: str(),
+			},
+			Response: &HTTPResponse{
+				Headers:         headers(),
+				Finished:        b(),
+				HeadersSent:     b(),
+				TransferSize:    i64(),
+				EncodedBodySize: i64(),
+				DecodedBodySize: i64(),
+				StatusCode:      int32(r.Intn(600)),
+			},
+			Version: str(),
+		},
+		UserAgent: &UserAgent{Original: str(), Name: str()},
+		Host: &Host{
+			Os:           &OS{Name: str(), Version: str(), Platform: str(), Full: str(), Type: str()},
+			Hostname:     str(),
+			Name:         str(),
+			Id:           str(),
+			Architecture: str(),
+			Type:         str(),
+			Ip:           []string{ip(), ip()},
+		},
+		Url: &URL{
+			Original: str(),
+			Scheme:   str(),
+			Full:     str(),
+			Domain:   str(),
+			Path:     str(),
+			Query:    str(),
+			Fragment: str(),
+			Port:     uint32(r.Intn(65536)),
+		},
+		Log: &Log{
+			Level:  str(),
+			Logger: str(),
+			Origin: &LogOrigin{
+				FunctionName: str(),
+				File:         &LogOriginFile{Name: str(), Line: r.Int31()},
+			},
+			Structured: st(),
+		},
+		Source: &Source{
+			Ip:     ip(),
+			Nat:    &NAT{Ip: ip()},
+			Domain: str(),
+			Port:   uint32(r.Intn(65536)),
+		},
+		Client:      &Client{Ip: ip(), Domain: str(), Port: uint32(r.Intn(65536))},
+		Destination: &Destination{Address: str(), Port: uint32(r.Intn(65536))},
+		Session:     &Session{Id: str(), Sequence: r.Int63()},
+		Process: &Process{
+			Ppid:        r.Uint32(),
+			Thread:      &ProcessThread{Name: str(), Id: r.Int31()},
+			Title:       str(),
+			CommandLine: str(),
+			Executable:  str(),
+			Argv:        strs(),
+			Pid:         r.Uint32(),
+		},
+		Event: &Event{
+			Outcome:      str(),
+			Action:       str(),
+			Dataset:      str(),
+			Kind:         str(),
+			Category:     str(),
+			Type:         str(),
+			Received:     timestamppb.New(ms()),
+			SuccessCount: summary(),
+			Duration:     durationpb.New(time.Duration(1 + r.Int63())),
+			Severity:     r.Int63(),
+		},
+	}
+
+	switch {
+	case e.Processor.IsTransaction(), e.Processor.IsSpan(), e.Processor.IsError():
+		// timestamp.us records microsecond precision for these events.
+		e.Timestamp = timestamppb.New(ms().Add(time.Duration(r.Intn(1000)) * time.Microsecond))
+	}
+
+	if e.Processor.IsTransaction() || e.Processor.IsMetricset() {
+		e.Transaction = &Transaction{
+			SpanCount: &SpanCount{Started: u32(), Dropped: u32()},
+			UserExperience: &UserExperience{
+				CumulativeLayoutShift: r.Float64(),
+				FirstInputDelay:       r.Float64(),
+				TotalBlockingTime:     r.Float64(),
+				LongTask: &LongtaskMetrics{
+					Count: r.Int63n(1000),
+					Sum:   r.Float64(),
+					Max:   r.Float64(),
+				},
+			},
+			Custom:              st(),
+			Marks:               map[string]*TransactionMark{str(): {Measurements: map[string]float64{str(): r.Float64()}}},
+			Message:             message(),
+			Type:                str(),
+			Name:                str(),
+			Result:              str(),
+			Id:                  str(),
+			DurationHistogram:   histogram(),
+			DurationSummary:     summary(),
+			RepresentativeCount: r.Float64(),
+			Sampled:             maybe(),
+			Root:                maybe(),
+		}
+		if e.Processor.IsMetricset() {
+			// Dropped span stats are only serialized for metrics.
+			e.Transaction.DroppedSpansStats = []*DroppedSpanStats{{
+				DestinationServiceResource: str(),
+				ServiceTargetType:          str(),
+				ServiceTargetName:          str(),
+				Outcome:                    str(),
+				Duration:                   aggregatedDuration(),
+			}}
+		}
+	}
+
+	if e.Processor.IsSpan() || e.Processor.IsMetricset() {
+		e.Span = &Span{
+			Message: message(),
+			Composite: &Composite{
+				CompressionStrategy: CompressionStrategy(1 + r.Intn(2)),
+				Count:               r.Uint32(),
+				Sum:                 float64(r.Intn(1e6)) / 4, // exact in microseconds
+			},
+			DestinationService: &DestinationService{
+				Type:         str(),
+				Name:         str(),
+				Resource:     str(),
+				ResponseTime: aggregatedDuration(),
+			},
+			Db: &DB{
+				RowsAffected: u32(),
+				Instance:     str(),
+				Statement:    str(),
+				Type:         str(),
+				UserName:     str(),
+				Link:         str(),
+			},
+			GenAi: &GenAI{
+				System:        str(),
+				OperationName: str(),
+				RequestModel:  str(),
+				ResponseModel: str(),
+				InputTokens:   u64(),
+				OutputTokens:  u64(),
+			},
+			Sync:                b(),
+			Kind:                str(),
+			Action:              str(),
+			Subtype:             str(),
+			Id:                  str(),
+			Type:                str(),
+			Name:                str(),
+			Stacktrace:          frames(),
+			Links:               []*SpanLink{{TraceId: str(), SpanId: str()}},
+			SelfTime:            aggregatedDuration(),
+			RepresentativeCount: r.Float64(),
+		}
+	}
+
+	if e.Processor.IsMetricset() {
+		e.Metricset = &Metricset{
+			Name:     str(),
+			Interval: str(),
+			DocCount: 1 + r.Int63n(1000),
+			Samples: []*MetricsetSample{
+				{Type: MetricType_METRIC_TYPE_HISTOGRAM, Name: str(), Unit: str(), Histogram: histogram()},
+				{Type: MetricType_METRIC_TYPE_SUMMARY, Name: str(), Unit: str(), Summary: summary()},
+				{Type: MetricType_METRIC_TYPE_COUNTER, Name: str(), Unit: str(), Temporality: AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE, Value: r.Float64(), Exemplars: []*Exemplar{{
+					Timestamp:     timestamppb.New(ms()),
+					Value:         r.Float64(),
+					TraceId:       str(),
+					SpanId:        str(),
+					Labels:        map[string]*LabelValue{str(): {Value: str()}},
+					NumericLabels: map[string]*NumericLabelValue{str(): {Value: r.Float64()}},
+				}}},
+				{Type: MetricType_METRIC_TYPE_GAUGE, Name: str(), Value: r.Float64()},
+				{Name: str(), Value: r.Float64()},
+			},
+		}
+	}
+
+	if e.Processor.IsError() {
+		e.Error = &Error{
+			Custom:    st(),
+			Exception: exception(0),
+			Log: &ErrorLog{
+				Message:      str(),
+				Level:        str(),
+				ParamMessage: str(),
+				LoggerName:   str(),
+				Stacktrace:   frames(),
+			},
+			Id:          str(),
+			GroupingKey: str(),
+			Culprit:     str(),
+			StackTrace:  str(),
+			Message:     str(),
+			Type:        str(),
+		}
+	}
+	return e
+}
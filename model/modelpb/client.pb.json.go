@@ -34,3 +34,11 @@ func (c *Client) toModelJSON(out *modeljson.Client) {
 		}
 	}
 }
+
+func (c *Client) fromModelJSON(in *modeljson.Client) {
+	*c = Client{
+		Ip:     in.IP,
+		Domain: in.Domain,
+		Port:   uint32(in.Port),
+	}
+}
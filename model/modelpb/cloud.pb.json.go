@@ -57,3 +57,27 @@ func (c *Cloud) toModelJSON(out *modeljson.Cloud) {
 	}
 
 }
+
+func (c *Cloud) fromModelJSON(in *modeljson.Cloud) {
+	*c = Cloud{
+		AvailabilityZone: in.AvailabilityZone,
+		Provider:         in.Provider,
+		Region:           in.Region,
+		AccountId:        in.Account.ID,
+		AccountName:      in.Account.Name,
+		ServiceName:      in.Service.Name,
+		ProjectId:        in.Project.ID,
+		ProjectName:      in.Project.Name,
+		InstanceId:       in.Instance.ID,
+		InstanceName:     in.Instance.Name,
+		MachineType:      in.Machine.Type,
+	}
+	if in.Origin != (modeljson.CloudOrigin{}) {
+		c.Origin = &CloudOrigin{
+			Provider:    in.Origin.Provider,
+			Region:      in.Origin.Region,
+			AccountId:   in.Origin.Account.ID,
+			ServiceName: in.Origin.Service.Name,
+		}
+	}
+}
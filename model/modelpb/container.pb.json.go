@@ -30,3 +30,13 @@ func (c *Container) toModelJSON(out *modeljson.Container) {
 		},
 	}
 }
+
+func (c *Container) fromModelJSON(in *modeljson.Container) {
+	*c = Container{
+		Id:        in.ID,
+		Name:      in.Name,
+		Runtime:   in.Runtime,
+		ImageName: in.Image.Name,
+		ImageTag:  in.Image.Tag,
+	}
+}
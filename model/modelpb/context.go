@@ -17,6 +17,8 @@
 
 package modelpb
 
+import "google.golang.org/protobuf/types/known/structpb"
+
 // updateFields transforms in, returning a copy with sanitized keys,
 // suitable for storing as "custom" in transaction and error documents.
 func updateFields(in map[string]any) {
@@ -27,3 +29,15 @@ func updateFields(in map[string]any) {
 		}
 	}
 }
+
+// newStruct converts in, a map decoded by encoding/json, into a structpb.Struct.
+//
+// All values produced by encoding/json are representable by structpb, so
+// conversion cannot fail in practice; should it fail, nil is returned.
+func newStruct(in map[string]any) *structpb.Struct {
+	s, err := structpb.NewStruct(in)
+	if err != nil {
+		return nil
+	}
+	return s
+}
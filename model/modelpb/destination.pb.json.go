@@ -34,3 +34,10 @@ func (d *Destination) toModelJSON(out *modeljson.Destination) {
 		}
 	}
 }
+
+func (d *Destination) fromModelJSON(in *modeljson.Destination) {
+	*d = Destination{
+		Address: in.Address,
+		Port:    uint32(in.Port),
+	}
+}
@@ -31,3 +31,16 @@ func (d *Device) toModelJSON(out *modeljson.Device) {
 		}
 	}
 }
+
+func (d *Device) fromModelJSON(in *modeljson.Device) {
+	*d = Device{
+		Id:           in.ID,
+		Manufacturer: in.Manufacturer,
+	}
+	if in.Model != (modeljson.DeviceModel{}) {
+		d.Model = &DeviceModel{
+			Name:       in.Model.Name,
+			Identifier: in.Model.Identifier,
+		}
+	}
+}
@@ -83,3 +83,63 @@ func (e *Exception) toModelJSON(out *modeljson.Exception) {
 		}
 	}
 }
+
+func (e *Error) fromModelJSON(in *modeljson.Error) {
+	*e = Error{
+		Id:          in.ID,
+		GroupingKey: in.GroupingKey,
+		Culprit:     in.Culprit,
+		Message:     in.Message,
+		Type:        in.Type,
+		StackTrace:  in.StackTrace,
+	}
+	if in.Custom != nil {
+		e.Custom = newStruct(in.Custom)
+	}
+	if in.Exception != nil {
+		e.Exception = &Exception{}
+		e.Exception.fromModelJSON(in.Exception)
+	}
+	if in.Log != nil {
+		e.Log = &ErrorLog{
+			Message:      in.Log.Message,
+			ParamMessage: in.Log.ParamMessage,
+			LoggerName:   in.Log.LoggerName,
+			Level:        in.Log.Level,
+		}
+		if n := len(in.Log.Stacktrace); n > 0 {
+			e.Log.Stacktrace = make([]*StacktraceFrame, n)
+			for i := range in.Log.Stacktrace {
+				e.Log.Stacktrace[i] = &StacktraceFrame{}
+				e.Log.Stacktrace[i].fromModelJSON(&in.Log.Stacktrace[i])
+			}
+		}
+	}
+}
+
+func (e *Exception) fromModelJSON(in *modeljson.Exception) {
+	*e = Exception{
+		Message: in.Message,
+		Module:  in.Module,
+		Code:    in.Code,
+		Type:    in.Type,
+		Handled: in.Handled,
+	}
+	if attrs, ok := in.Attributes.(map[string]any); ok {
+		e.Attributes = newStruct(attrs)
+	}
+	if n := len(in.Cause); n > 0 {
+		e.Cause = make([]*Exception, n)
+		for i := range in.Cause {
+			e.Cause[i] = &Exception{}
+			e.Cause[i].fromModelJSON(&in.Cause[i])
+		}
+	}
+	if n := len(in.Stacktrace); n > 0 {
+		e.Stacktrace = make([]*StacktraceFrame, n)
+		for i := range in.Stacktrace {
+			e.Stacktrace[i] = &StacktraceFrame{}
+			e.Stacktrace[i].fromModelJSON(&in.Stacktrace[i])
+		}
+	}
+}
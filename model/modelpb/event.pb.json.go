@@ -18,7 +18,11 @@
 package modelpb
 
 import (
+	"time"
+
 	"github.com/elastic/apm-data/model/internal/modeljson"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (e *Event) toModelJSON(out *modeljson.Event) {
@@ -42,3 +46,27 @@ func (e *Event) toModelJSON(out *modeljson.Event) {
 		out.Received = modeljson.Time(e.Received.AsTime())
 	}
 }
+
+func (e *Event) fromModelJSON(in *modeljson.Event) {
+	*e = Event{
+		Outcome:  in.Outcome,
+		Action:   in.Action,
+		Dataset:  in.Dataset,
+		Kind:     in.Kind,
+		Category: in.Category,
+		Type:     in.Type,
+		Severity: in.Severity,
+	}
+	if in.Duration != 0 {
+		e.Duration = durationpb.New(time.Duration(in.Duration))
+	}
+	if in.SuccessCount != (modeljson.SummaryMetric{}) {
+		e.SuccessCount = &SummaryMetric{
+			Count: in.SuccessCount.Count,
+			Sum:   in.SuccessCount.Sum,
+		}
+	}
+	if received := time.Time(in.Received); !received.IsZero() {
+		e.Received = timestamppb.New(received)
+	}
+}
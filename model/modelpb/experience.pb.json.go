@@ -33,3 +33,16 @@ func (u *UserExperience) toModelJSON(out *modeljson.UserExperience) {
 		}
 	}
 }
+
+func (u *UserExperience) fromModelJSON(in *modeljson.UserExperience) {
+	*u = UserExperience{
+		CumulativeLayoutShift: in.CumulativeLayoutShift,
+		FirstInputDelay:       in.FirstInputDelay,
+		TotalBlockingTime:     in.TotalBlockingTime,
+		LongTask: &LongtaskMetrics{
+			Count: int64(in.Longtask.Count),
+			Sum:   in.Longtask.Sum,
+			Max:   in.Longtask.Max,
+		},
+	}
+}
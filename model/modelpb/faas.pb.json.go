@@ -32,3 +32,15 @@ func (f *Faas) toModelJSON(out *modeljson.FAAS) {
 		},
 	}
 }
+
+func (f *Faas) fromModelJSON(in *modeljson.FAAS) {
+	*f = Faas{
+		Id:               in.ID,
+		Name:             in.Name,
+		Version:          in.Version,
+		Execution:        in.Execution,
+		ColdStart:        in.Coldstart,
+		TriggerType:      in.Trigger.Type,
+		TriggerRequestId: in.Trigger.RequestID,
+	}
+}
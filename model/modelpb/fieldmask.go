@@ -0,0 +1,136 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package modelpb
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// FieldMask selects a subset of APMEvent fields, identified by their
+// proto field paths, e.g. "trace.id" or "service.name".
+//
+// Selecting a message field, e.g. "service", selects all of its fields.
+// Paths may not traverse repeated or map fields.
+type FieldMask struct {
+	root fieldMaskNode
+}
+
+// fieldMaskNode holds the selected children of a message field. A nil
+// fieldMaskNode selects all fields.
+type fieldMaskNode map[protoreflect.FieldNumber]fieldMaskNode
+
+// NewFieldMask returns a FieldMask selecting the given field paths,
+// or an error if a path does not identify an APMEvent field.
+func NewFieldMask(paths ...string) (*FieldMask, error) {
+	root := make(fieldMaskNode)
+	desc := (*APMEvent)(nil).ProtoReflect().Descriptor()
+	for _, path := range paths {
+		if err := root.add(desc, path); err != nil {
+			return nil, err
+		}
+	}
+	return &FieldMask{root: root}, nil
+}
+
+func (n fieldMaskNode) add(desc protoreflect.MessageDescriptor, path string) error {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		fd := desc.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return fmt.Errorf("invalid field mask path %q: unknown field %q in %s", path, name, desc.FullName())
+		}
+		child, ok := n[fd.Number()]
+		if ok && child == nil {
+			// An ancestor has already been selected in full.
+			return nil
+		}
+		if i == len(names)-1 {
+			n[fd.Number()] = nil
+			return nil
+		}
+		if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+			return fmt.Errorf("invalid field mask path %q: cannot traverse field %q", path, name)
+		}
+		if child == nil {
+			child = make(fieldMaskNode)
+			n[fd.Number()] = child
+		}
+		n, desc = child, fd.Message()
+	}
+	return nil
+}
+
+// Project returns a new event holding only the fields of e selected by
+// the mask.
+//
+// The returned event shares memory with e, and is intended for encoding.
+// It must not be modified, and e must not be modified while it is in use.
+func (m *FieldMask) Project(e *APMEvent) *APMEvent {
+	out := &APMEvent{}
+	if e != nil {
+		m.root.project(out.ProtoReflect(), e.ProtoReflect())
+	}
+	return out
+}
+
+func (n fieldMaskNode) project(dst, src protoreflect.Message) {
+	fields := src.Descriptor().Fields()
+	for num, child := range n {
+		fd := fields.ByNumber(num)
+		if !src.Has(fd) {
+			continue
+		}
+		if child == nil {
+			dst.Set(fd, src.Get(fd))
+			continue
+		}
+		child.project(dst.Mutable(fd).Message(), src.Get(fd).Message())
+	}
+}
+
+// Strip clears, in place, the fields of e not selected by the mask.
+func (m *FieldMask) Strip(e *APMEvent) {
+	if e != nil {
+		m.root.strip(e.ProtoReflect())
+	}
+}
+
+// StripBatch clears, in place, the fields not selected by the mask from
+// each event in b.
+func (m *FieldMask) StripBatch(b Batch) {
+	for _, e := range b {
+		m.Strip(e)
+	}
+}
+
+func (n fieldMaskNode) strip(msg protoreflect.Message) {
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		child, ok := n[fd.Number()]
+		switch {
+		case !ok:
+			msg.Clear(fd)
+		case child != nil:
+			child.strip(v.Message())
+		}
+		return true
+	})
+	msg.SetUnknown(nil)
+}
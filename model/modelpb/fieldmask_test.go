@@ -0,0 +1,93 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package modelpb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestFieldMask(t *testing.T) {
+	mask, err := NewFieldMask(
+		"timestamp",
+		"trace.id",
+		"service",
+		"service.name", // already selected by "service"
+		"span.destination_service.response_time.count",
+		"labels",
+	)
+	require.NoError(t, err)
+
+	event := fullEvent(t)
+	expected := &APMEvent{
+		Timestamp: timestamppb.New(time.Unix(1, 1)),
+		Trace:     &Trace{Id: event.Trace.Id},
+		Service:   event.Service,
+		Span: &Span{
+			DestinationService: &DestinationService{
+				ResponseTime: &AggregatedDuration{Count: 3},
+			},
+		},
+		Labels: event.Labels,
+	}
+	orig := proto.Clone(event)
+
+	projected := mask.Project(event)
+	assert.Empty(t, cmp.Diff(expected, projected, protocmp.Transform()))
+	assert.Empty(t, cmp.Diff(orig, event, protocmp.Transform()), "Project must not modify its input")
+
+	mask.StripBatch(Batch{event, nil})
+	assert.Empty(t, cmp.Diff(expected, event, protocmp.Transform()))
+
+	assert.Empty(t, cmp.Diff(&APMEvent{}, mask.Project(nil), protocmp.Transform()))
+}
+
+func TestFieldMaskEmpty(t *testing.T) {
+	mask, err := NewFieldMask()
+	require.NoError(t, err)
+
+	event := &APMEvent{
+		Message: "message",
+		Span:    &Span{SelfTime: &AggregatedDuration{Sum: durationpb.New(time.Second)}},
+	}
+	assert.Empty(t, cmp.Diff(&APMEvent{}, mask.Project(event), protocmp.Transform()))
+	mask.Strip(event)
+	assert.Empty(t, cmp.Diff(&APMEvent{}, event, protocmp.Transform()))
+}
+
+func TestFieldMaskInvalid(t *testing.T) {
+	for path, expected := range map[string]string{
+		"unknown":          `invalid field mask path "unknown": unknown field "unknown" in elastic.apm.v1.APMEvent`,
+		"trace.unknown":    `invalid field mask path "trace.unknown": unknown field "unknown" in elastic.apm.v1.Trace`,
+		"message.foo":      `invalid field mask path "message.foo": cannot traverse field "message"`,
+		"labels.foo":       `invalid field mask path "labels.foo": cannot traverse field "labels"`,
+		"span.links.trace": `invalid field mask path "span.links.trace": cannot traverse field "links"`,
+		"":                 `invalid field mask path "": unknown field "" in elastic.apm.v1.APMEvent`,
+	} {
+		_, err := NewFieldMask(path)
+		assert.EqualError(t, err, expected, path)
+	}
+}
@@ -48,3 +48,18 @@ func (h *Host) toModelJSON(out *modeljson.Host) {
 		out.OS = &os
 	}
 }
+
+func (h *Host) fromModelJSON(in *modeljson.Host) {
+	*h = Host{
+		Hostname:     in.Hostname,
+		Name:         in.Name,
+		Id:           in.ID,
+		Architecture: in.Architecture,
+		Type:         in.Type,
+		Ip:           in.IP,
+	}
+	if in.OS != nil {
+		h.Os = &OS{}
+		h.Os.fromModelJSON(in.OS)
+	}
+}
@@ -19,6 +19,9 @@ package modelpb
 
 import (
 	"net/http"
+	"sort"
+
+	"google.golang.org/protobuf/types/known/structpb"
 
 	"github.com/elastic/apm-data/model/internal/modeljson"
 )
@@ -73,3 +76,57 @@ func ToHTTPHeaders(h []*HTTPHeader) http.Header {
 	}
 	return headers
 }
+
+func (h *HTTP) fromModelJSON(in *modeljson.HTTP) {
+	*h = HTTP{
+		Version: in.Version,
+	}
+	if in.Request != nil {
+		h.Request = &HTTPRequest{
+			Id:       in.Request.ID,
+			Method:   in.Request.Method,
+			Referrer: in.Request.Referrer,
+			Headers:  FromHTTPHeaders(in.Request.Headers),
+		}
+		if in.Request.Env != nil {
+			h.Request.Env = newStruct(in.Request.Env)
+		}
+		if in.Request.Cookies != nil {
+			h.Request.Cookies = newStruct(in.Request.Cookies)
+		}
+		if in.Request.Body != nil {
+			if body, err := structpb.NewValue(in.Request.Body.Original); err == nil {
+				h.Request.Body = body
+			}
+		}
+	}
+	if in.Response != nil {
+		h.Response = &HTTPResponse{
+			StatusCode:      int32(in.Response.StatusCode),
+			Finished:        in.Response.Finished,
+			HeadersSent:     in.Response.HeadersSent,
+			TransferSize:    in.Response.TransferSize,
+			EncodedBodySize: in.Response.EncodedBodySize,
+			DecodedBodySize: in.Response.DecodedBodySize,
+			Headers:         FromHTTPHeaders(in.Response.Headers),
+		}
+	}
+}
+
+// FromHTTPHeaders is the inverse of ToHTTPHeaders. The returned
+// headers are sorted by key.
+func FromHTTPHeaders(h map[string][]string) []*HTTPHeader {
+	if len(h) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	headers := make([]*HTTPHeader, len(keys))
+	for i, k := range keys {
+		headers[i] = &HTTPHeader{Key: k, Value: h[k]}
+	}
+	return headers
+}
@@ -31,3 +31,12 @@ func (k *Kubernetes) toModelJSON(out *modeljson.Kubernetes) {
 		},
 	}
 }
+
+func (k *Kubernetes) fromModelJSON(in *modeljson.Kubernetes) {
+	*k = Kubernetes{
+		Namespace: in.Namespace,
+		NodeName:  in.Node.Name,
+		PodName:   in.Pod.Name,
+		PodUid:    in.Pod.UID,
+	}
+}
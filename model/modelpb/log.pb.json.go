@@ -35,4 +35,30 @@ func (l *Log) toModelJSON(out *modeljson.Log) {
 			}
 		}
 	}
+	if l.Structured != nil {
+		m := l.Structured.AsMap()
+		updateFields(m)
+		out.Structured = m
+	}
+}
+
+func (l *Log) fromModelJSON(in *modeljson.Log) {
+	*l = Log{
+		Level:  in.Level,
+		Logger: in.Logger,
+	}
+	if in.Origin != (modeljson.LogOrigin{}) {
+		l.Origin = &LogOrigin{
+			FunctionName: in.Origin.Function,
+		}
+		if in.Origin.File != (modeljson.LogOriginFile{}) {
+			l.Origin.File = &LogOriginFile{
+				Name: in.Origin.File.Name,
+				Line: int32(in.Origin.File.Line),
+			}
+		}
+	}
+	if in.Structured != nil {
+		l.Structured = newStruct(in.Structured)
+	}
 }
@@ -23,6 +23,7 @@ import (
 	"github.com/elastic/apm-data/model/internal/modeljson"
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 func TestLogToModelJSON(t *testing.T) {
@@ -55,6 +56,12 @@ func TestLogToModelJSON(t *testing.T) {
 						Line: 1,
 					},
 				},
+				Structured: &structpb.Struct{
+					Fields: map[string]*structpb.Value{
+						"foo.bar": structpb.NewStringValue("baz"),
+						"count":   structpb.NewNumberValue(1),
+					},
+				},
 			},
 			expected: &modeljson.Log{
 				Level:  "level",
@@ -66,6 +73,10 @@ func TestLogToModelJSON(t *testing.T) {
 						Line: 1,
 					},
 				},
+				Structured: map[string]any{
+					"foo_bar": "baz",
+					"count":   float64(1),
+				},
 			},
 		},
 	}
@@ -36,3 +36,13 @@ func (m *Message) toModelJSON(out *modeljson.Message) {
 		out.Headers = headers
 	}
 }
+
+func (m *Message) fromModelJSON(in *modeljson.Message) {
+	*m = Message{
+		Body:       in.Body,
+		Headers:    FromHTTPHeaders(in.Headers),
+		AgeMillis:  in.Age.Millis,
+		QueueName:  in.Queue.Name,
+		RoutingKey: in.RoutingKey,
+	}
+}
@@ -17,7 +17,12 @@
 
 package modelpb
 
-import "github.com/elastic/apm-data/model/internal/modeljson"
+import (
+	"time"
+
+	"github.com/elastic/apm-data/model/internal/modeljson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
 
 var metricTypeText = map[MetricType]string{
 	MetricType_METRIC_TYPE_GAUGE:     "gauge",
@@ -26,6 +31,23 @@ var metricTypeText = map[MetricType]string{
 	MetricType_METRIC_TYPE_SUMMARY:   "summary",
 }
 
+var metricTypeValue = map[string]MetricType{
+	"gauge":     MetricType_METRIC_TYPE_GAUGE,
+	"counter":   MetricType_METRIC_TYPE_COUNTER,
+	"histogram": MetricType_METRIC_TYPE_HISTOGRAM,
+	"summary":   MetricType_METRIC_TYPE_SUMMARY,
+}
+
+var aggregationTemporalityText = map[AggregationTemporality]string{
+	AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA:      "delta",
+	AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE: "cumulative",
+}
+
+var aggregationTemporalityValue = map[string]AggregationTemporality{
+	"delta":      AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+	"cumulative": AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+}
+
 func (me *Metricset) toModelJSON(out *modeljson.Metricset) {
 	var samples []modeljson.MetricsetSample
 	if n := len(me.Samples); n > 0 {
@@ -33,10 +55,11 @@ func (me *Metricset) toModelJSON(out *modeljson.Metricset) {
 		for i, sample := range me.Samples {
 			if sample != nil {
 				sampleJson := modeljson.MetricsetSample{
-					Name:  sample.Name,
-					Type:  metricTypeText[sample.Type],
-					Unit:  sample.Unit,
-					Value: sample.Value,
+					Name:        sample.Name,
+					Type:        metricTypeText[sample.Type],
+					Unit:        sample.Unit,
+					Temporality: aggregationTemporalityText[sample.Temporality],
+					Value:       sample.Value,
 				}
 				if sample.Histogram != nil {
 					sampleJson.Histogram = modeljson.Histogram{
@@ -50,6 +73,14 @@ func (me *Metricset) toModelJSON(out *modeljson.Metricset) {
 						Sum:   sample.Summary.Sum,
 					}
 				}
+				if n := len(sample.Exemplars); n > 0 {
+					sampleJson.Exemplars = make([]modeljson.Exemplar, 0, n)
+					for _, exemplar := range sample.Exemplars {
+						if exemplar != nil {
+							sampleJson.Exemplars = append(sampleJson.Exemplars, exemplar.toModelJSON())
+						}
+					}
+				}
 
 				samples[i] = sampleJson
 			}
@@ -61,3 +92,101 @@ func (me *Metricset) toModelJSON(out *modeljson.Metricset) {
 		Samples:  samples,
 	}
 }
+
+func (me *Metricset) fromModelJSON(in *modeljson.Metricset) {
+	*me = Metricset{
+		Name:     in.Name,
+		Interval: in.Interval,
+	}
+	if n := len(in.Samples); n > 0 {
+		me.Samples = make([]*MetricsetSample, n)
+		for i, sampleJson := range in.Samples {
+			sample := &MetricsetSample{
+				Name:        sampleJson.Name,
+				Type:        metricTypeValue[sampleJson.Type],
+				Unit:        sampleJson.Unit,
+				Temporality: aggregationTemporalityValue[sampleJson.Temporality],
+				Value:       sampleJson.Value,
+			}
+			switch sample.Type {
+			case MetricType_METRIC_TYPE_HISTOGRAM:
+				sample.Histogram = &Histogram{
+					Values: sampleJson.Histogram.Values,
+					Counts: sampleJson.Histogram.Counts,
+				}
+			case MetricType_METRIC_TYPE_SUMMARY:
+				sample.Summary = &SummaryMetric{
+					Count: sampleJson.Summary.Count,
+					Sum:   sampleJson.Summary.Sum,
+				}
+			}
+			if n := len(sampleJson.Exemplars); n > 0 {
+				sample.Exemplars = make([]*Exemplar, n)
+				for j := range sampleJson.Exemplars {
+					sample.Exemplars[j] = exemplarFromModelJSON(&sampleJson.Exemplars[j])
+				}
+			}
+			me.Samples[i] = sample
+		}
+	}
+}
+
+func (e *Exemplar) toModelJSON() modeljson.Exemplar {
+	out := modeljson.Exemplar{
+		Timestamp: modeljson.Time(e.Timestamp.AsTime()),
+		Value:     e.Value,
+		TraceID:   e.TraceId,
+		SpanID:    e.SpanId,
+	}
+	if n := len(e.Labels); n > 0 {
+		out.Labels = make(map[string]modeljson.Label, n)
+		for k, label := range e.Labels {
+			if label != nil {
+				out.Labels[sanitizeLabelKey(k)] = modeljson.Label{
+					Value:  label.Value,
+					Values: label.Values,
+				}
+			}
+		}
+	}
+	if n := len(e.NumericLabels); n > 0 {
+		out.NumericLabels = make(map[string]modeljson.NumericLabel, n)
+		for k, label := range e.NumericLabels {
+			if label != nil {
+				out.NumericLabels[sanitizeLabelKey(k)] = modeljson.NumericLabel{
+					Value:  label.Value,
+					Values: label.Values,
+				}
+			}
+		}
+	}
+	return out
+}
+
+func exemplarFromModelJSON(in *modeljson.Exemplar) *Exemplar {
+	e := &Exemplar{
+		Timestamp: timestamppb.New(time.Time(in.Timestamp)),
+		Value:     in.Value,
+		TraceId:   in.TraceID,
+		SpanId:    in.SpanID,
+	}
+	if n := len(in.Labels); n > 0 {
+		e.Labels = make(map[string]*LabelValue, n)
+		for k, label := range in.Labels {
+			e.Labels[k] = &LabelValue{
+				Value:  label.Value,
+				Values: label.Values,
+			}
+		}
+	}
+	if n := len(in.NumericLabels); n > 0 {
+		e.NumericLabels = make(map[string]*NumericLabelValue, n)
+		for k, label := range in.NumericLabels {
+			e.NumericLabels[k] = &NumericLabelValue{
+				Value:  label.Value,
+				Values: label.Values,
+			}
+		}
+	}
+	return e
+}
@@ -19,10 +19,12 @@ package modelpb
 
 import (
 	"testing"
+	"time"
 
 	"github.com/elastic/apm-data/model/internal/modeljson"
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestMetricsetToModelJSON(t *testing.T) {
@@ -40,9 +42,10 @@ func TestMetricsetToModelJSON(t *testing.T) {
 				Interval: "interval",
 				Samples: []*MetricsetSample{
 					{
-						Type: MetricType_METRIC_TYPE_COUNTER,
-						Name: "name",
-						Unit: "unit",
+						Type:        MetricType_METRIC_TYPE_COUNTER,
+						Name:        "name",
+						Unit:        "unit",
+						Temporality: AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
 						Histogram: &Histogram{
 							Values: []float64{1},
 							Counts: []int64{2},
@@ -52,6 +55,14 @@ func TestMetricsetToModelJSON(t *testing.T) {
 							Sum:   4,
 						},
 						Value: 5,
+						Exemplars: []*Exemplar{{
+							Timestamp:     timestamppb.New(time.Unix(1, 0)),
+							Value:         6,
+							TraceId:       "trace_id",
+							SpanId:        "span_id",
+							Labels:        map[string]*LabelValue{"a.b": {Value: "c"}},
+							NumericLabels: map[string]*NumericLabelValue{"d": {Value: 7}},
+						}},
 					},
 				},
 				DocCount: 1,
@@ -61,9 +72,10 @@ func TestMetricsetToModelJSON(t *testing.T) {
 				Interval: "interval",
 				Samples: []modeljson.MetricsetSample{
 					{
-						Type: "counter",
-						Name: "name",
-						Unit: "unit",
+						Type:        "counter",
+						Name:        "name",
+						Unit:        "unit",
+						Temporality: "delta",
 						Histogram: modeljson.Histogram{
 							Values: []float64{1},
 							Counts: []int64{2},
@@ -73,6 +85,14 @@ func TestMetricsetToModelJSON(t *testing.T) {
 							Sum:   4,
 						},
 						Value: 5,
+						Exemplars: []modeljson.Exemplar{{
+							Timestamp:     modeljson.Time(time.Unix(1, 0)),
+							Value:         6,
+							TraceID:       "trace_id",
+							SpanID:        "span_id",
+							Labels:        map[string]modeljson.Label{"a_b": {Value: "c"}},
+							NumericLabels: map[string]modeljson.NumericLabel{"d": {Value: 7}},
+						}},
 					},
 				},
 			},
@@ -82,7 +102,10 @@ func TestMetricsetToModelJSON(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			var out modeljson.Metricset
 			tc.proto.toModelJSON(&out)
-			diff := cmp.Diff(*tc.expected, out)
+			diff := cmp.Diff(*tc.expected, out,
+				cmp.Comparer(func(a modeljson.Time, b modeljson.Time) bool {
+					return time.Time(a).Equal(time.Time(b))
+				}))
 			require.Empty(t, diff)
 		})
 	}
@@ -36,3 +36,21 @@ func (n *Network) toModelJSON(out *modeljson.Network) {
 		}
 	}
 }
+
+func (n *Network) fromModelJSON(in *modeljson.Network) {
+	*n = Network{}
+	if in.Connection != (modeljson.NetworkConnection{}) {
+		n.Connection = &NetworkConnection{
+			Type:    in.Connection.Type,
+			Subtype: in.Connection.Subtype,
+		}
+	}
+	if in.Carrier != (modeljson.NetworkCarrier{}) {
+		n.Carrier = &NetworkCarrier{
+			Name: in.Carrier.Name,
+			Mcc:  in.Carrier.MCC,
+			Mnc:  in.Carrier.MNC,
+			Icc:  in.Carrier.ICC,
+		}
+	}
+}
@@ -27,3 +27,12 @@ func (o *Observer) toModelJSON(out *modeljson.Observer) {
 		Version:  o.Version,
 	}
 }
+
+func (o *Observer) fromModelJSON(in *modeljson.Observer) {
+	*o = Observer{
+		Hostname: in.Hostname,
+		Name:     in.Name,
+		Type:     in.Type,
+		Version:  in.Version,
+	}
+}
@@ -28,3 +28,13 @@ func (o *OS) toModelJSON(out *modeljson.OS) {
 		Type:     o.Type,
 	}
 }
+
+func (o *OS) fromModelJSON(in *modeljson.OS) {
+	*o = OS{
+		Name:     in.Name,
+		Version:  in.Version,
+		Platform: in.Platform,
+		Full:     in.Full,
+		Type:     in.Type,
+	}
+}
@@ -37,3 +37,20 @@ func (p *Process) toModelJSON(out *modeljson.Process) {
 		}
 	}
 }
+
+func (p *Process) fromModelJSON(in *modeljson.Process) {
+	*p = Process{
+		Pid:         uint32(in.Pid),
+		Ppid:        in.Parent.Pid,
+		Title:       in.Title,
+		CommandLine: in.CommandLine,
+		Executable:  in.Executable,
+		Argv:        in.Args,
+	}
+	if in.Thread != (modeljson.ProcessThread{}) {
+		p.Thread = &ProcessThread{
+			Name: in.Thread.Name,
+			Id:   int32(in.Thread.ID),
+		}
+	}
+}
@@ -62,3 +62,47 @@ func (s *Service) toModelJSON(out *modeljson.Service) {
 		}
 	}
 }
+
+func (s *Service) fromModelJSON(in *modeljson.Service) {
+	*s = Service{
+		Name:        in.Name,
+		Version:     in.Version,
+		Environment: in.Environment,
+	}
+	if in.Node != nil {
+		s.Node = &ServiceNode{
+			Name: in.Node.Name,
+		}
+	}
+	if in.Language != nil {
+		s.Language = &Language{
+			Name:    in.Language.Name,
+			Version: in.Language.Version,
+		}
+	}
+	if in.Runtime != nil {
+		s.Runtime = &Runtime{
+			Name:    in.Runtime.Name,
+			Version: in.Runtime.Version,
+		}
+	}
+	if in.Framework != nil {
+		s.Framework = &Framework{
+			Name:    in.Framework.Name,
+			Version: in.Framework.Version,
+		}
+	}
+	if in.Origin != nil {
+		s.Origin = &ServiceOrigin{
+			Id:      in.Origin.ID,
+			Name:    in.Origin.Name,
+			Version: in.Origin.Version,
+		}
+	}
+	if in.Target != nil {
+		s.Target = &ServiceTarget{
+			Name: in.Target.Name,
+			Type: in.Target.Type,
+		}
+	}
+}
@@ -37,3 +37,16 @@ func (s *Source) toModelJSON(out *modeljson.Source) {
 		}
 	}
 }
+
+func (s *Source) fromModelJSON(in *modeljson.Source) {
+	*s = Source{
+		Domain: in.Domain,
+		Port:   uint32(in.Port),
+	}
+	if ip := netip.Addr(in.IP); ip.IsValid() {
+		s.Ip = ip.String()
+	}
+	if ip := netip.Addr(in.NAT.IP); ip.IsValid() {
+		s.Nat = &NAT{Ip: ip.String()}
+	}
+}
@@ -20,6 +20,8 @@ package modelpb
 import (
 	"time"
 
+	"google.golang.org/protobuf/types/known/durationpb"
+
 	"github.com/elastic/apm-data/model/internal/modeljson"
 )
 
@@ -28,6 +30,11 @@ var compressionStrategyText = map[CompressionStrategy]string{
 	CompressionStrategy_COMPRESSION_STRATEGY_SAME_KIND:   "same_kind",
 }
 
+var compressionStrategyValue = map[string]CompressionStrategy{
+	"exact_match": CompressionStrategy_COMPRESSION_STRATEGY_EXACT_MATCH,
+	"same_kind":   CompressionStrategy_COMPRESSION_STRATEGY_SAME_KIND,
+}
+
 func (db *DB) toModelJSON(out *modeljson.DB) {
 	*out = modeljson.DB{
 		Instance:     db.Instance,
@@ -39,6 +46,19 @@ func (db *DB) toModelJSON(out *modeljson.DB) {
 	}
 }
 
+func (g *GenAI) toModelJSON(out *modeljson.GenAI) {
+	*out = modeljson.GenAI{
+		System:    g.System,
+		Operation: modeljson.GenAIOperation{Name: g.OperationName},
+		Request:   modeljson.GenAIModel{Model: g.RequestModel},
+		Response:  modeljson.GenAIModel{Model: g.ResponseModel},
+		Usage: modeljson.GenAIUsage{
+			InputTokens:  g.InputTokens,
+			OutputTokens: g.OutputTokens,
+		},
+	}
+}
+
 func (c *Composite) toModelJSON(out *modeljson.SpanComposite) {
 	sumDuration := time.Duration(c.Sum * float64(time.Millisecond))
 	*out = modeljson.SpanComposite{
@@ -73,6 +93,10 @@ func (e *Span) toModelJSON(out *modeljson.Span) {
 		out.Message = &modeljson.Message{}
 		e.Message.toModelJSON(out.Message)
 	}
+	if e.GenAi != nil {
+		out.GenAI = &modeljson.GenAI{}
+		e.GenAi.toModelJSON(out.GenAI)
+	}
 	if e.Composite != nil {
 		out.Composite = &modeljson.SpanComposite{}
 		e.Composite.toModelJSON(out.Composite)
@@ -108,3 +132,98 @@ func (e *Span) toModelJSON(out *modeljson.Span) {
 		}
 	}
 }
+
+func (db *DB) fromModelJSON(in *modeljson.DB) {
+	*db = DB{
+		Instance:     in.Instance,
+		Statement:    in.Statement,
+		Type:         in.Type,
+		Link:         in.Link,
+		RowsAffected: in.RowsAffected,
+		UserName:     in.User.Name,
+	}
+}
+
+func (g *GenAI) fromModelJSON(in *modeljson.GenAI) {
+	*g = GenAI{
+		System:        in.System,
+		OperationName: in.Operation.Name,
+		RequestModel:  in.Request.Model,
+		ResponseModel: in.Response.Model,
+		InputTokens:   in.Usage.InputTokens,
+		OutputTokens:  in.Usage.OutputTokens,
+	}
+}
+
+func (c *Composite) fromModelJSON(in *modeljson.SpanComposite) {
+	sumDuration := time.Duration(in.Sum.US) * time.Microsecond
+	*c = Composite{
+		CompressionStrategy: compressionStrategyValue[in.CompressionStrategy],
+		Count:               uint32(in.Count),
+		Sum:                 float64(sumDuration) / float64(time.Millisecond),
+	}
+}
+
+func (e *Span) fromModelJSON(in *modeljson.Span) {
+	*e = Span{
+		Id:                  in.ID,
+		Name:                in.Name,
+		Type:                in.Type,
+		Subtype:             in.Subtype,
+		Action:              in.Action,
+		Kind:                in.Kind,
+		Sync:                in.Sync,
+		RepresentativeCount: in.RepresentativeCount,
+	}
+	if in.SelfTime != (modeljson.AggregatedDuration{}) {
+		e.SelfTime = &AggregatedDuration{
+			Count: int64(in.SelfTime.Count),
+			Sum:   durationpb.New(in.SelfTime.Sum),
+		}
+	}
+	if in.DB != nil {
+		e.Db = &DB{}
+		e.Db.fromModelJSON(in.DB)
+	}
+	if in.Message != nil {
+		e.Message = &Message{}
+		e.Message.fromModelJSON(in.Message)
+	}
+	if in.GenAI != nil {
+		e.GenAi = &GenAI{}
+		e.GenAi.fromModelJSON(in.GenAI)
+	}
+	if in.Composite != nil {
+		e.Composite = &Composite{}
+		e.Composite.fromModelJSON(in.Composite)
+	}
+	if in.Destination != nil {
+		e.DestinationService = &DestinationService{
+			Type:     in.Destination.Service.Type,
+			Name:     in.Destination.Service.Name,
+			Resource: in.Destination.Service.Resource,
+		}
+		if rt := in.Destination.Service.ResponseTime; rt != (modeljson.AggregatedDuration{}) {
+			e.DestinationService.ResponseTime = &AggregatedDuration{
+				Count: int64(rt.Count),
+				Sum:   durationpb.New(rt.Sum),
+			}
+		}
+	}
+	if n := len(in.Links); n > 0 {
+		e.Links = make([]*SpanLink, n)
+		for i, link := range in.Links {
+			e.Links[i] = &SpanLink{
+				TraceId: link.Trace.ID,
+				SpanId:  link.Span.ID,
+			}
+		}
+	}
+	if n := len(in.Stacktrace); n > 0 {
+		e.Stacktrace = make([]*StacktraceFrame, n)
+		for i := range in.Stacktrace {
+			e.Stacktrace[i] = &StacktraceFrame{}
+			e.Stacktrace[i].fromModelJSON(&in.Stacktrace[i])
+		}
+	}
+}
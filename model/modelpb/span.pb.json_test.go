@@ -82,6 +82,14 @@ func TestSpanToModelJSON(t *testing.T) {
 					UserName:     "db_username",
 					Link:         "db_link",
 				},
+				GenAi: &GenAI{
+					System:        "openai",
+					OperationName: "chat",
+					RequestModel:  "gpt-4",
+					ResponseModel: "gpt-4-0613",
+					InputTokens:   uint64Ptr(9),
+					OutputTokens:  uint64Ptr(10),
+				},
 				Sync:    &sync,
 				Kind:    "kind",
 				Action:  "action",
@@ -130,6 +138,16 @@ func TestSpanToModelJSON(t *testing.T) {
 					},
 					Link: "db_link",
 				},
+				GenAI: &modeljson.GenAI{
+					System:    "openai",
+					Operation: modeljson.GenAIOperation{Name: "chat"},
+					Request:   modeljson.GenAIModel{Model: "gpt-4"},
+					Response:  modeljson.GenAIModel{Model: "gpt-4-0613"},
+					Usage: modeljson.GenAIUsage{
+						InputTokens:  uint64Ptr(9),
+						OutputTokens: uint64Ptr(10),
+					},
+				},
 				Sync:    &sync,
 				Kind:    "kind",
 				Action:  "action",
@@ -73,3 +73,47 @@ func (s *StacktraceFrame) toModelJSON(out *modeljson.StacktraceFrame) {
 		}
 	}
 }
+
+func (s *StacktraceFrame) fromModelJSON(in *modeljson.StacktraceFrame) {
+	*s = StacktraceFrame{
+		Filename:            in.Filename,
+		Classname:           in.Classname,
+		AbsPath:             in.AbsPath,
+		Module:              in.Module,
+		Function:            in.Function,
+		LibraryFrame:        in.LibraryFrame,
+		ExcludeFromGrouping: in.ExcludeFromGrouping,
+	}
+
+	if in.Vars != nil {
+		s.Vars = newStruct(in.Vars)
+	}
+
+	if in.Context != nil {
+		s.PreContext = in.Context.Pre
+		s.PostContext = in.Context.Post
+	}
+
+	if in.Line != nil {
+		s.Lineno = in.Line.Number
+		s.Colno = in.Line.Column
+		s.ContextLine = in.Line.Context
+	}
+
+	if in.Sourcemap != nil {
+		s.SourcemapUpdated = in.Sourcemap.Updated
+		s.SourcemapError = in.Sourcemap.Error
+	}
+
+	if in.Original != nil {
+		s.Original = &Original{
+			Filename:     in.Original.Filename,
+			Classname:    in.Original.Classname,
+			AbsPath:      in.Original.AbsPath,
+			Function:     in.Original.Function,
+			Colno:        in.Original.Colno,
+			Lineno:       in.Original.Lineno,
+			LibraryFrame: in.Original.LibraryFrame,
+		}
+	}
+}
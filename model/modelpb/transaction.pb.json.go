@@ -17,7 +17,11 @@
 
 package modelpb
 
-import "github.com/elastic/apm-data/model/internal/modeljson"
+import (
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/elastic/apm-data/model/internal/modeljson"
+)
 
 func (e *Transaction) toModelJSON(out *modeljson.Transaction, metricset bool) {
 	*out = modeljson.Transaction{
@@ -101,3 +105,71 @@ func (e *Transaction) toModelJSON(out *modeljson.Transaction, metricset bool) {
 		}
 	}
 }
+
+func (e *Transaction) fromModelJSON(in *modeljson.Transaction) {
+	*e = Transaction{
+		Id:                  in.ID,
+		Type:                in.Type,
+		Name:                in.Name,
+		Result:              in.Result,
+		Sampled:             in.Sampled,
+		Root:                in.Root,
+		RepresentativeCount: in.RepresentativeCount,
+	}
+
+	if in.Custom != nil {
+		e.Custom = newStruct(in.Custom)
+	}
+
+	if n := len(in.Marks); n > 0 {
+		e.Marks = make(map[string]*TransactionMark, n)
+		for k, mark := range in.Marks {
+			e.Marks[k] = &TransactionMark{Measurements: mark}
+		}
+	}
+	if in.Message != nil {
+		e.Message = &Message{}
+		e.Message.fromModelJSON(in.Message)
+	}
+	if in.UserExperience != nil {
+		e.UserExperience = &UserExperience{}
+		e.UserExperience.fromModelJSON(in.UserExperience)
+	}
+	if n := len(in.DroppedSpansStats); n > 0 {
+		e.DroppedSpansStats = make([]*DroppedSpanStats, n)
+		for i, dssJson := range in.DroppedSpansStats {
+			dss := &DroppedSpanStats{
+				DestinationServiceResource: dssJson.DestinationServiceResource,
+				ServiceTargetType:          dssJson.ServiceTargetType,
+				ServiceTargetName:          dssJson.ServiceTargetName,
+				Outcome:                    dssJson.Outcome,
+			}
+			if dssJson.Duration != (modeljson.AggregatedDuration{}) {
+				dss.Duration = &AggregatedDuration{
+					Count: int64(dssJson.Duration.Count),
+					Sum:   durationpb.New(dssJson.Duration.Sum),
+				}
+			}
+			e.DroppedSpansStats[i] = dss
+		}
+	}
+
+	if in.DurationHistogram.Values != nil || in.DurationHistogram.Counts != nil {
+		e.DurationHistogram = &Histogram{
+			Values: in.DurationHistogram.Values,
+			Counts: in.DurationHistogram.Counts,
+		}
+	}
+	if in.DurationSummary != (modeljson.SummaryMetric{}) {
+		e.DurationSummary = &SummaryMetric{
+			Count: in.DurationSummary.Count,
+			Sum:   in.DurationSummary.Sum,
+		}
+	}
+	if in.SpanCount != (modeljson.SpanCount{}) {
+		e.SpanCount = &SpanCount{
+			Dropped: in.SpanCount.Dropped,
+			Started: in.SpanCount.Started,
+		}
+	}
+}
@@ -31,3 +31,16 @@ func (u *URL) toModelJSON(out *modeljson.URL) {
 		Port:     int(u.Port),
 	}
 }
+
+func (u *URL) fromModelJSON(in *modeljson.URL) {
+	*u = URL{
+		Original: in.Original,
+		Scheme:   in.Scheme,
+		Full:     in.Full,
+		Domain:   in.Domain,
+		Path:     in.Path,
+		Query:    in.Query,
+		Fragment: in.Fragment,
+		Port:     uint32(in.Port),
+	}
+}
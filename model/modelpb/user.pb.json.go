@@ -27,3 +27,12 @@ func (u *User) toModelJSON(out *modeljson.User) {
 		Name:   u.Name,
 	}
 }
+
+func (u *User) fromModelJSON(in *modeljson.User) {
+	*u = User{
+		Domain: in.Domain,
+		Id:     in.ID,
+		Email:  in.Email,
+		Name:   in.Name,
+	}
+}
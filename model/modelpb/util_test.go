@@ -59,6 +59,10 @@ func int64Ptr(i int64) *int64 {
 	return &i
 }
 
+func uint64Ptr(i uint64) *uint64 {
+	return &i
+}
+
 func boolPtr(b bool) *bool {
 	return &b
 }